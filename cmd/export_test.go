@@ -0,0 +1,72 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/outscale/octl/pkg/config"
+)
+
+// CompareRules compares two rule sets, written as JSON, and returns the rules to add and to remove, prefixed by their flow.
+func CompareRules(current, wanted string) (added, removed []string, err error) {
+	var cur, want sgRuleSet
+	if err := json.Unmarshal([]byte(current), &cur); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal([]byte(wanted), &want); err != nil {
+		return nil, nil, err
+	}
+	changes := compareRules(cur, want)
+	for _, flow := range []string{"Inbound", "Outbound"} {
+		for _, r := range rules(flow, changes.added) {
+			added = append(added, flow+" "+r.String())
+		}
+		for _, r := range rules(flow, changes.removed) {
+			removed = append(removed, flow+" "+r.String())
+		}
+	}
+	return added, removed, nil
+}
+
+// ConsoleStart returns the position of the first byte of a console output not displayed yet, seen being displayed.
+func ConsoleStart(seen, out string) int {
+	f := consoleFollower{seen: seen}
+	return f.start(out)
+}
+
+// SyncEntry is a file or an object found by a sync.
+type SyncEntry struct {
+	Size    int64
+	ModTime time.Time
+	ETag    string
+}
+
+// SyncDiffers returns true if the destination entry needs to be replaced by the source entry.
+func SyncDiffers(src, dst SyncEntry) bool {
+	s := &syncEntry{size: src.Size, modTime: src.ModTime, etag: src.ETag}
+	return s.differs(&syncEntry{size: dst.Size, modTime: dst.ModTime, etag: dst.ETag})
+}
+
+// SyncMatch returns true if a file is synchronized with the include and exclude patterns.
+func SyncMatch(include, exclude []string, rel string) (bool, error) {
+	f, err := newSyncFilter(include, exclude)
+	if err != nil {
+		return false, err
+	}
+	return f.match(rel), nil
+}
+
+// InventoryCalls returns the calls made by an inventory, by entity.
+func InventoryCalls(cfg config.Config, ct reflect.Type) map[string]string {
+	calls := map[string]string{}
+	for _, c := range inventoryCalls(cfg, ct) {
+		calls[c.entity] = c.call
+	}
+	return calls
+}
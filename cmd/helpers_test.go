@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/outscale/octl/pkg/testserver"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,15 +31,27 @@ func runWithError(t *testing.T, args []string, input []byte) {
 }
 
 func try(ctx context.Context, args []string, input []byte) ([]byte, error) {
+	stdout := &bytes.Buffer{}
+	err := octl(ctx, args, input, stdout, os.Stderr)
+	return stdout.Bytes(), err
+}
+
+// octl runs octl with args, input being used as its standard input.
+func octl(ctx context.Context, args []string, input []byte, stdout, stderr io.Writer) error {
 	cmd := exec.CommandContext(ctx, "go", append([]string{"run", "../main.go"}, args...)...)
 	if len(input) > 0 {
 		cmd.Stdin = bytes.NewBuffer(input)
 	}
-	stdout := &bytes.Buffer{}
 	cmd.Stdout = stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	return stdout.Bytes(), err
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// tryStderr runs octl, returning its error output.
+func tryStderr(ctx context.Context, args []string, input []byte) ([]byte, error) {
+	stderr := &bytes.Buffer{}
+	err := octl(ctx, args, input, io.Discard, io.MultiWriter(stderr, os.Stderr))
+	return stderr.Bytes(), err
 }
 
 func retry(t *testing.T, args []string, input []byte) {
@@ -99,3 +114,23 @@ func runJSON(t *testing.T, args []string, input []byte, resp any) {
 	err := json.Unmarshal(content, &resp)
 	require.NoError(t, err)
 }
+
+// mock starts a test server, and returns the flags required to target it.
+func mock(t *testing.T) (*testserver.Server, []string) {
+	t.Helper()
+	srv := testserver.New()
+	t.Cleanup(srv.Close)
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := srv.WriteProfile(path, "test")
+	require.NoError(t, err)
+	return srv, []string{"--config", path, "--profile", "test", "--no-upgrade"}
+}
+
+// mockArgs starts a test server, and returns a function appending the flags required to target it to arguments.
+func mockArgs(t *testing.T) (*testserver.Server, func(args ...string) []string) {
+	t.Helper()
+	srv, flags := mock(t)
+	return srv, func(args ...string) []string {
+		return append(args, flags...)
+	}
+}
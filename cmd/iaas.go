@@ -8,7 +8,6 @@ package cmd
 import (
 	"reflect"
 	"strings"
-	"time"
 
 	"github.com/outscale/octl/pkg/builder"
 	"github.com/outscale/octl/pkg/config"
@@ -34,14 +33,43 @@ func init() {
 	}, oapi)
 	b.Build(iaasCmd, nil)
 
-	cmd, _, err := iaasCmd.Find([]string{"net"})
+	for entity, e := range map[string]dependencyEntity{
+		"net":          {service: "iaas", name: "net", arg: "net_id", resolve: netGraph, check: checkNetVMs},
+		"vm":           {service: "iaas", name: "vm", arg: "vm_id", resolve: vmGraph},
+		"loadbalancer": {service: "iaas", name: "load balancer", arg: "load_balancer_name", resolve: loadBalancerGraph},
+	} {
+		cmd, _, err := iaasCmd.Find([]string{entity})
+		if err != nil {
+			panic(err)
+		}
+		depsCmd, teardownCmd := dependencyCommands(e)
+		if entity == "net" {
+			teardownCmd.Flags().Bool("teardown-vms", false, "Tears down VM in net")
+		}
+		cmd.AddCommand(depsCmd, teardownCmd)
+	}
+
+	cmd, _, err := iaasCmd.Find([]string{"tag"})
+	if err != nil {
+		panic(err)
+	}
+	cmd.AddCommand(tagFindCmd, tagApplyCmd, tagRemoveCmd)
+
+	cmd, _, err = iaasCmd.Find([]string{"securitygroup"})
 	if err != nil {
 		panic(err)
 	}
-	cmd.AddCommand(teardownCmd)
-	teardownCmd.Flags().Duration("timeout", 10*time.Minute, "Timeout for a single resource deletion")
-	teardownCmd.Flags().Bool("teardown-vms", false, "Tears down VM in net")
-	cmd.AddCommand(depsCmd)
+	cmd.AddCommand(securityGroupAuditCmd, securityGroupSyncCmd, securityGroupExportCmd)
+
+	cmd, _, err = iaasCmd.Find([]string{"vm"})
+	if err != nil {
+		panic(err)
+	}
+	cmd.AddCommand(vmSSHCmd, vmConsoleCmd)
+
+	iaasCmd.AddCommand(applyCmd, inventoryCmd, diffCmd, orphansCmd)
+	applyCmd.Flags().StringP("file", "f", "", "Manifest file describing the resources to create")
+	applyCmd.Flags().Bool(runner.PlanFlag, false, "display the resources that would be created, without creating them")
 }
 
 func oapi(cmd *cobra.Command, args []string) {
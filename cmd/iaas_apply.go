@@ -0,0 +1,230 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/diff"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/octl/pkg/manifest"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f manifest",
+	Short: "Creates all resources described in a manifest",
+	Long: `Creates all resources described in a YAML manifest.
+
+Resources reference each other with the ${kind.name.Attribute} syntax, e.g. ${net.main.NetId}.
+Resources are created in dependency order, then tagged, then additional calls (e.g. links) are made.
+
+Example of manifest:
+
+  resources:
+    - kind: net
+      name: main
+      spec:
+        IpRange: 10.0.0.0/16
+      tags:
+        Name: main
+    - kind: subnet
+      name: public
+      spec:
+        NetId: ${net.main.NetId}
+        IpRange: 10.0.1.0/24`,
+	Example: "octl iaas apply -f stack.yaml",
+	Run:     apply,
+}
+
+// applyKind describes how to create a kind of resource.
+type applyKind struct {
+	typ graph.Type
+	// call is the creation call, without the Create prefix.
+	call string
+	// result is the response attribute storing the created resource.
+	// If it is a list, the first item is used.
+	result string
+	id     string
+}
+
+var applyKinds = map[string]applyKind{
+	"net":             {typ: Net, call: "Net", result: "Net", id: "NetId"},
+	"subnet":          {typ: Subnet, call: "Subnet", result: "Subnet", id: "SubnetId"},
+	"internetService": {typ: InternetService, call: "InternetService", result: "InternetService", id: "InternetServiceId"},
+	"netPeering":      {typ: NetPeering, call: "NetPeering", result: "NetPeering", id: "NetPeeringId"},
+	"virtualGateway":  {typ: VirtualGateway, call: "VirtualGateway", result: "VirtualGateway", id: "VirtualGatewayId"},
+	"vpnConnection":   {typ: VPNConnection, call: "VpnConnection", result: "VpnConnection", id: "VpnConnectionId"},
+	"natService":      {typ: NATService, call: "NatService", result: "NatService", id: "NatServiceId"},
+	"routeTable":      {typ: RouteTable, call: "RouteTable", result: "RouteTable", id: "RouteTableId"},
+	"securityGroup":   {typ: SecurityGroup, call: "SecurityGroup", result: "SecurityGroup", id: "SecurityGroupId"},
+	"loadBalancer":    {typ: LoadBalancer, call: "LoadBalancer", result: "LoadBalancer", id: "LoadBalancerName"},
+	"netAccessPoint":  {typ: NetAccessPoint, call: "NetAccessPoint", result: "NetAccessPoint", id: "NetAccessPointId"},
+	"publicIp":        {typ: PublicIP, call: "PublicIp", result: "PublicIp", id: "PublicIpId"},
+	"vm":              {typ: VM, call: "Vms", result: "Vms", id: "VmId"},
+	"nic":             {typ: NIC, call: "Nic", result: "Nic", id: "NicId"},
+}
+
+// creationOrder orders kinds having no dependency between them.
+var creationOrder = []graph.Type{
+	Net,
+	Subnet,
+	SecurityGroup,
+	InternetService,
+	PublicIP,
+	NIC,
+	NetPeering,
+	VirtualGateway,
+	VPNConnection,
+	NATService,
+	NetAccessPoint,
+	RouteTable,
+	VM,
+	LoadBalancer,
+}
+
+func creationRank(kind string) int {
+	return slices.Index(creationOrder, applyKinds[kind].typ)
+}
+
+func apply(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	path, _ := cmd.Flags().GetString("file")
+	if path == "" {
+		messages.ExitErr(errors.New("a manifest is required (--file)"))
+	}
+	m, err := manifest.Load(path)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	for _, r := range m.Resources {
+		if _, found := applyKinds[r.Kind]; !found {
+			kinds := lo.Keys(applyKinds)
+			sort.Strings(kinds)
+			messages.ExitErr(fmt.Errorf("%s: unsupported kind %q, supported kinds are %v", r, r.Kind, kinds))
+		}
+	}
+	order, err := m.Order(creationRank)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if planned, _ := cmd.Flags().GetBool(runner.PlanFlag); planned {
+		planApply(order)
+		return
+	}
+
+	p := loadProfile(cmd)
+	cl, err := osc.NewClient(p, sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	state := manifest.State{}
+	created := map[string]*graph.Node{}
+	g := graph.New()
+	for _, r := range order {
+		res, err := applyResource(cmd.Context(), cl, r, state)
+		if res != nil {
+			// resources are displayed below the first resource they reference
+			var parent *graph.Node
+			if deps := r.Dependencies(); len(deps) > 0 {
+				parent = created[deps[0]]
+			}
+			created[r.Ref()] = g.Add(parent, res)
+		}
+		if err != nil {
+			writeApplySummary(g)
+			messages.ExitErr(fmt.Errorf("unable to apply %s: %w", r, err))
+		}
+		messages.Success("%s was created.", res)
+	}
+	writeApplySummary(g)
+}
+
+func writeApplySummary(g *graph.Graph) {
+	if err := g.Write(os.Stdout); err != nil {
+		messages.ExitErr(err)
+	}
+}
+
+// applyResource creates a resource, tags it, then makes all additional calls.
+// The resource is returned as soon as it is created, even if a later step fails.
+func applyResource(ctx context.Context, cl *osc.Client, r *manifest.Resource, state manifest.State) (*graph.Node, error) {
+	cancel := spinner.Run(ctx, "Creating "+r.Ref()+" ...")
+	defer cancel()
+	k := applyKinds[r.Kind]
+	spec, err := state.Resolve(r.Spec)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := runner.CallJSON(ctx, cl, "Create"+k.call, spec)
+	if err != nil {
+		return nil, err
+	}
+	obj := resp[k.result]
+	if list, ok := obj.([]any); ok && len(list) > 0 {
+		obj = list[0]
+	}
+	attrs, ok := obj.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("no %s found in response", k.result)
+	}
+	state[r.Ref()] = attrs
+	id := fmt.Sprint(attrs[k.id])
+	res := &graph.Node{Type: k.typ, ID: id, Name: r.Name}
+
+	if len(r.Tags) > 0 {
+		keys := lo.Keys(r.Tags)
+		sort.Strings(keys)
+		tags := lo.Map(keys, func(key string, _ int) osc.ResourceTag { return osc.ResourceTag{Key: key, Value: r.Tags[key]} })
+		if _, err := cl.CreateTags(ctx, osc.CreateTagsRequest{ResourceIds: []string{id}, Tags: tags}); err != nil {
+			return res, fmt.Errorf("tag %s: %w", id, err)
+		}
+	}
+	for _, c := range r.Calls {
+		spec, err := state.Resolve(c.Spec)
+		if err != nil {
+			return res, err
+		}
+		if _, err := runner.CallJSON(ctx, cl, c.Call, spec); err != nil {
+			return res, fmt.Errorf("%s: %w", c.Call, err)
+		}
+	}
+	return res, nil
+}
+
+// planApply displays the resources that would be created, references being displayed unresolved.
+func planApply(order []*manifest.Resource) {
+	for _, r := range order {
+		planned := map[string]any{}
+		if len(r.Spec) > 0 {
+			planned["spec"] = r.Spec
+		}
+		if len(r.Tags) > 0 {
+			planned["tags"] = r.Tags
+		}
+		if len(r.Calls) > 0 {
+			planned["calls"] = lo.Map(r.Calls, func(c manifest.Call, _ int) map[string]any { return map[string]any{c.Call: c.Spec} })
+		}
+		changes, err := diff.Compare(nil, map[string]any{r.Ref(): planned})
+		if err == nil {
+			err = diff.Write(os.Stdout, changes)
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+}
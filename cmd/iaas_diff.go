@@ -0,0 +1,209 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/diff"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff previous.json [next.json]",
+	Short: "Compares two snapshots of resources, or a snapshot with the live state",
+	Long: `Compares two snapshots of resources, or a snapshot with the live state.
+
+Snapshots are written by octl iaas <entity> list -o json, or by octl iaas inventory. Resources are matched by their primary ID,
+and added, removed and updated resources are reported, with all changed fields. Tags are compared by key.
+
+The entity of a list is detected from its primary ID, and can be set with --entity.`,
+	Example: `octl iaas vm list -o json > vms.json
+octl iaas diff vms.json
+octl iaas inventory > before.json && octl iaas diff before.json after.json`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  diffSnapshots,
+}
+
+func init() {
+	diffCmd.Flags().String("entity", "", "entity of the listed resources, e.g. vm")
+	diffCmd.Flags().Int(alias.ParallelFlag, 8, "maximum number of concurrent calls, when reading the live state")
+}
+
+// drift is the change of a resource, or of a field of a resource.
+type drift struct {
+	Entity   string `json:"Entity"`
+	ID       string `json:"Id"`
+	Change   string `json:"Change"`
+	Field    string `json:"Field,omitempty"`
+	Previous any    `json:"Previous,omitempty"`
+	Next     any    `json:"Next,omitempty"`
+}
+
+var driftColumns = config.Columns{
+	{Title: "Entity", Content: ".Entity"},
+	{Title: "ID", Content: ".Id"},
+	{Title: "Change", Content: ".Change"},
+	{Title: "Field", Content: ".Field"},
+	{Title: "Previous", Content: ".Previous"},
+	{Title: "Next", Content: ".Next"},
+}
+
+var changeNames = map[diff.Kind]string{
+	diff.Added:   "added",
+	diff.Removed: "removed",
+	diff.Updated: "updated",
+}
+
+func diffSnapshots(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	cfg := config.For("iaas")
+	entity, _ := cmd.Flags().GetString("entity")
+	previous, err := loadSnapshot(cfg, args[0], entity)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	var next map[string][]any
+	if len(args) > 1 {
+		next, err = loadSnapshot(cfg, args[1], entity)
+	} else {
+		next, err = liveSnapshot(cmd, cfg, lo.Keys(previous))
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+
+	entities := lo.Union(lo.Keys(previous), lo.Keys(next))
+	slices.Sort(entities)
+	drifts := []drift{}
+	for _, e := range entities {
+		changes, err := diff.CompareSnapshots(cfg.Entities[e].Primary, previous[e], next[e])
+		if err != nil {
+			messages.ExitErr(fmt.Errorf("%s: %w", e, err))
+		}
+		for _, rc := range changes {
+			if rc.Kind != diff.Updated {
+				drifts = append(drifts, drift{Entity: e, ID: rc.ID, Change: changeNames[rc.Kind]})
+				continue
+			}
+			for _, c := range rc.Changes {
+				drifts = append(drifts, drift{
+					Entity: e, ID: rc.ID, Change: changeNames[c.Kind],
+					Field: c.Path, Previous: c.Current, Next: c.Requested,
+				})
+			}
+		}
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", driftColumns, false, false)
+	if err == nil {
+		err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(drifts))
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+}
+
+// loadSnapshot loads the resources of a snapshot, keyed by entity.
+// A snapshot is either a list of resources of a single entity, or an inventory keyed by entity.
+// Entities without primary ID are ignored.
+func loadSnapshot(cfg config.Config, path, entity string) (map[string][]any, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var content any
+	if err := yaml.Unmarshal(buf, &content); err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	switch content := content.(type) {
+	case []any:
+		if entity == "" {
+			entity, err = detectEntity(cfg, content)
+			if err != nil {
+				return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+			}
+		}
+		if cfg.Entities[entity].Primary == "" {
+			return nil, fmt.Errorf("read snapshot %s: %s resources have no primary ID", path, entity)
+		}
+		return map[string][]any{entity: content}, nil
+	case map[string]any:
+		snapshot := map[string][]any{}
+		for e, v := range content {
+			list, ok := v.([]any)
+			if !ok || cfg.Entities[e].Primary == "" || (entity != "" && e != entity) {
+				debug.Println("snapshot: ignoring", e)
+				continue
+			}
+			snapshot[e] = list
+		}
+		return snapshot, nil
+	default:
+		return nil, fmt.Errorf("read snapshot %s: neither a list of resources nor an inventory", path)
+	}
+}
+
+// detectEntity returns the only entity whose primary ID is a field of the listed resources.
+func detectEntity(cfg config.Config, resources []any) (string, error) {
+	if len(resources) == 0 {
+		return "", fmt.Errorf("unable to detect the entity of an empty list, use --entity")
+	}
+	first, _ := resources[0].(map[string]any)
+	var candidates []string
+	for name, e := range cfg.Entities {
+		if _, found := first[e.Primary]; e.Primary != "" && found {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) != 1 {
+		slices.Sort(candidates)
+		return "", fmt.Errorf("unable to detect the entity of the resources (candidates: %s), use --entity", strings.Join(candidates, ", "))
+	}
+	return candidates[0], nil
+}
+
+// liveSnapshot reads the current resources of entities.
+func liveSnapshot(cmd *cobra.Command, cfg config.Config, entities []string) (map[string][]any, error) {
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	calls := lo.Filter(inventoryCalls(cfg, reflect.TypeOf(cl)), func(c inventoryCall, _ int) bool {
+		return slices.Contains(entities, c.entity)
+	})
+	if missing, _ := lo.Difference(entities, lo.Map(calls, func(c inventoryCall, _ int) string { return c.entity })); len(missing) > 0 {
+		return nil, fmt.Errorf("unable to read the live state of %s", strings.Join(missing, ", "))
+	}
+	parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+	cancel := spinner.Run(cmd.Context(), "Reading live state...")
+	inv, failed := readInventory(cmd.Context(), cl, calls, max(parallel, 1))
+	cancel()
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("read %s: %s", failed[0].Entity, failed[0].Error)
+	}
+	snapshot := map[string][]any{}
+	for e, v := range inv {
+		list, ok := v.([]any)
+		if !ok {
+			continue
+		}
+		snapshot[e] = list
+	}
+	return snapshot, nil
+}
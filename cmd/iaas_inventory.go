@@ -0,0 +1,258 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/output/read"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Reads all resources of the account, keyed by entity type",
+	Long: `Reads all resources of the account, keyed by entity type.
+
+All Read calls of entities having a content field are made, all pages being fetched. Calls requiring parameters are skipped.
+Calls failing are reported, the inventory containing all other entities, and the command exits with status 1.`,
+	Example: `octl iaas inventory -o yaml > inventory.yaml
+octl iaas inventory --dir inventory`,
+	Args: cobra.NoArgs,
+	Run:  inventory,
+}
+
+func init() {
+	inventoryCmd.Flags().Int(alias.ParallelFlag, 8, "maximum number of concurrent calls")
+	inventoryCmd.Flags().String("dir", "", "write one file per entity type in a directory, instead of a single document")
+}
+
+// inventoryCall is the Read call listing the resources of an entity.
+type inventoryCall struct {
+	entity  string
+	call    string
+	content string
+	// request is the request of the call, an empty request if nil.
+	request any
+}
+
+// inventoryCalls returns the Read calls having a content field and no required parameters, one per entity.
+// If an entity has several calls, a call listing resources (e.g. ReadCatalogs) is preferred to a call returning a single
+// object (e.g. ReadCatalog), the first call in alphabetical order being used otherwise.
+func inventoryCalls(cfg config.Config, ct reflect.Type) []inventoryCall {
+	byEntity := map[string]inventoryCall{}
+	lists := map[string]bool{}
+	names := lo.Keys(cfg.Calls)
+	slices.Sort(names)
+	for _, name := range names {
+		c := cfg.Calls[name]
+		if !strings.HasPrefix(name, "Read") || c.Content == "" || c.Entity == "" {
+			continue
+		}
+		m, found := ct.MethodByName(name)
+		if !found {
+			debug.Println("inventory: no method", name)
+			continue
+		}
+		if m.Type.NumIn() < 3 || requiresParams(m.Type.In(2)) {
+			debug.Println("inventory: skipping", name, "which requires parameters")
+			continue
+		}
+		list := listsContent(m.Type, c.Content)
+		if _, found := byEntity[c.Entity]; found && (lists[c.Entity] || !list) {
+			continue
+		}
+		byEntity[c.Entity] = inventoryCall{entity: c.Entity, call: name, content: c.Content}
+		lists[c.Entity] = list
+	}
+	calls := lo.Values(byEntity)
+	slices.SortFunc(calls, func(a, b inventoryCall) int { return strings.Compare(a.entity, b.entity) })
+	return calls
+}
+
+// listsContent returns true if the content field of the response of a method is a list.
+func listsContent(mt reflect.Type, content string) bool {
+	if mt.NumOut() == 0 {
+		return false
+	}
+	t := mt.Out(0)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	f, found := t.FieldByName(content)
+	if !found {
+		return false
+	}
+	t = f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice
+}
+
+// requiresParams returns true if a request has a required field, i.e. a field which is neither a pointer nor a slice.
+func requiresParams(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for f := range t.Fields() {
+		if f.Type.Kind() != reflect.Pointer && f.Type.Kind() != reflect.Slice {
+			return true
+		}
+	}
+	return false
+}
+
+// inventoryFailure is a call which has failed.
+type inventoryFailure struct {
+	Entity string
+	Call   string
+	Error  string
+}
+
+var inventoryFailureColumns = config.Columns{
+	{Title: "Entity", Content: ".Entity"},
+	{Title: "Call", Content: ".Call"},
+	{Title: "Error", Content: ".Error"},
+}
+
+func inventory(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	out, _ := cmd.Flags().GetString("output")
+	out = strings.ToLower(lo.CoalesceOrEmpty(out, "json"))
+	if out != "json" && out != "yaml" {
+		messages.ExitErr(fmt.Errorf("unsupported format %q for an inventory, use json or yaml", out))
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), out, "", nil, false, false)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+	calls := inventoryCalls(config.For("iaas"), reflect.TypeOf(cl))
+
+	cancel := spinner.Run(cmd.Context(), fmt.Sprintf("Reading %d entity types...", len(calls)))
+	inv, failed := readInventory(cmd.Context(), cl, calls, max(parallel, 1))
+	cancel()
+
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		err = fmter.Format(cmd.Context(), os.Stdout, inv)
+	} else {
+		err = writeInventoryDir(cmd.Context(), dir, out, fmter, inv)
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if len(failed) > 0 {
+		tbl := format.Tabular{Columns: inventoryFailureColumns, Formatter: format.TableFormatter{}}
+		if err := tbl.Format(cmd.Context(), os.Stderr, failed); err != nil {
+			messages.ExitErr(err)
+		}
+		messages.Exit(1, "%d of %d entity type(s) could not be read", len(failed), len(calls))
+	}
+}
+
+// readInventory makes all calls, with at most parallel concurrent calls.
+func readInventory(ctx context.Context, cl *osc.Client, calls []inventoryCall, parallel int) (map[string]any, []inventoryFailure) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		sem    = make(chan struct{}, parallel)
+		inv    = map[string]any{}
+		failed []inventoryFailure
+	)
+	for _, c := range calls {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			v, err := readAll(ctx, cl, c)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, inventoryFailure{Entity: c.entity, Call: c.call, Error: err.Error()})
+				return
+			}
+			inv[c.entity] = v
+		})
+	}
+	wg.Wait()
+	slices.SortFunc(failed, func(a, b inventoryFailure) int { return strings.Compare(a.Entity, b.Entity) })
+	return inv, failed
+}
+
+// readAll fetches all pages of a call, returning a list, or a single object if the content is not a list.
+func readAll(ctx context.Context, cl *osc.Client, c inventoryCall) (any, error) {
+	m := reflect.ValueOf(cl).MethodByName(c.call)
+	req := reflect.New(m.Type().In(1)).Elem()
+	if c.request != nil {
+		req.Set(reflect.ValueOf(c.request))
+	}
+	fetch := read.FetchPage{
+		Method: m,
+		Args:   []reflect.Value{reflect.ValueOf(ctx), req},
+		Quiet:  true,
+	}
+	items := []any{}
+	for r := range read.NewPaginated(c.content, 0, 0).Read(ctx, fetch) {
+		switch {
+		case r.Error != nil:
+			return nil, r.Error
+		case r.SingleEntry:
+			return r.Ok, nil
+		default:
+			items = append(items, r.Ok)
+		}
+	}
+	return items, nil
+}
+
+// writeInventoryDir writes one file per entity type in dir, named after the entity and the output format.
+func writeInventoryDir(ctx context.Context, dir, ext string, fmter format.Interface, inv map[string]any) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("inventory dir: %w", err)
+	}
+	for entity, v := range inv {
+		path := filepath.Join(dir, entity+"."+ext)
+		f, err := os.Create(path) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("inventory dir: %w", err)
+		}
+		err = fmter.Format(ctx, f, v)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/outscale/goutils/sdk/tags"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/spf13/cobra"
+)
+
+func loadBalancerGraph(cmd *cobra.Command, name string) (*graph.Graph, error) {
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	return listLoadBalancerResources(cmd.Context(), cl, name)
+}
+
+// listLoadBalancerResources builds the graph of a load balancer and of the resources it uses.
+// Backend VMs, and security groups and public IPs used by other resources, are listed, but are never deleted.
+func listLoadBalancerResources(ctx context.Context, cl *osc.Client, name string) (*graph.Graph, error) {
+	g := graph.New()
+	users, err := readResourceUsers(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+	lbus, err := cl.ReadLoadBalancers(ctx, osc.ReadLoadBalancersRequest{Filters: &osc.FiltersLoadBalancer{LoadBalancerNames: &[]string{name}}})
+	if err != nil {
+		return nil, fmt.Errorf("list load balancers: %w", err)
+	}
+	if len(*lbus.LoadBalancers) == 0 {
+		return nil, errors.New("no load balancer found")
+	}
+	lbu := (*lbus.LoadBalancers)[0]
+	root := g.Add(nil, &graph.Node{
+		Type:  LoadBalancer,
+		ID:    name,
+		Name:  tags.Must(tags.GetName(lbu.Tags)),
+		Calls: deleteLoadBalancer(name),
+	})
+
+	for _, sg := range lbu.SecurityGroups {
+		g.Use(root, &graph.Node{
+			Type:  SecurityGroup,
+			ID:    sg,
+			Calls: onlyUsedBy(users.securityGroups[sg], name, deleteSecurityGroup(sg)),
+		})
+	}
+	if lbu.PublicIp != nil {
+		pips, err := cl.ReadPublicIps(ctx, osc.ReadPublicIpsRequest{
+			Filters: &osc.FiltersPublicIp{PublicIps: &[]string{*lbu.PublicIp}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list public ip: %w", err)
+		}
+		for _, pip := range *pips.PublicIps {
+			g.Use(root, &graph.Node{
+				Type:  PublicIP,
+				ID:    pip.PublicIpId,
+				Name:  pip.PublicIp,
+				Calls: onlyUsedBy(users.publicIPs[pip.PublicIp], name, deletePublicIP(pip.PublicIpId)),
+			})
+		}
+	}
+	for _, id := range lbu.BackendVmIds {
+		g.Use(root, &graph.Node{Type: VM, ID: id})
+	}
+	return g, nil
+}
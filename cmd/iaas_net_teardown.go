@@ -6,216 +6,157 @@ SPDX-License-Identifier: BSD-3-Clause
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"time"
-
-	"github.com/outscale/octl/pkg/alias"
-	"github.com/outscale/octl/pkg/config"
-	"github.com/outscale/octl/pkg/debug"
-	"github.com/outscale/octl/pkg/messages"
-	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/octl/pkg/graph"
 	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
-	"github.com/spf13/cobra"
 )
 
-var teardownCmd = &cobra.Command{
-	Use:   "teardown net_id",
-	Short: "Tears down a net and its subresources",
-	Run:   alias.Confirm(config.ActionDelete, displayNet(true), teardownNet),
+// ignoreDeleteError ignores errors of deletion calls reporting that there is nothing left to delete.
+func ignoreDeleteError(call string, err error) error {
+	oerr := osc.AsErrorResponse(err)
+	switch {
+	case oerr == nil:
+		return err
+	case osc.IsNotFound(err):
+		return nil
+	case call == "DeleteSecurityGroup" && oerr.GetCode() == "9051":
+		return nil
+	}
+	return err
 }
 
-func teardownNet(cmd *cobra.Command, args []string) {
-	debug.Println(cmd.Name() + " called")
+func deleteNet(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteNet", Params: osc.DeleteNetRequest{NetId: id}}}
+}
 
-	if len(args) == 0 {
-		messages.ExitErr(fmt.Errorf("not enough arguments for %s", cmd.Name()))
-	}
-	netID := args[0]
-	p := loadProfile(cmd)
-	cl, err := osc.NewClient(p, sdkOptions(cmd)...)
-	if err != nil {
-		messages.ExitErr(err)
-	}
-	r, _, err := listResources(cmd.Context(), cl, netID)
-	if err != nil {
-		messages.ExitErr(err)
-	}
-	tmout, _ := cmd.Flags().GetDuration("timeout")
-	doTeardown(cmd.Context(), r, tmout)
-}
-
-var deletionOrder = []ResourceType{
-	LoadBalancer,
-	VM,
-	RouteTable,
-	NetAccessPoint,
-	NATService,
-	VPNConnection,
-	VirtualGateway,
-	NetPeering,
-	NIC,
-	PublicIP,
-	InternetService,
-	SecurityGroup,
-	Subnet,
-	Net,
-}
-
-func doTeardown(ctx context.Context, r *Resource, tmout time.Duration) {
-	for _, typ := range deletionOrder {
-		doTeardownType(ctx, r, typ, tmout)
-	}
+func deleteSubnet(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteSubnet", Params: osc.DeleteSubnetRequest{SubnetId: id}}}
 }
 
-func doTeardownType(ctx context.Context, r *Resource, typ ResourceType, tmout time.Duration) {
-	for _, c := range r.children {
-		doTeardownType(ctx, c, typ, tmout)
-	}
-	if r.delete == nil || r.Type != typ {
-		return
-	}
-	start := time.Now()
-	ctx, cancel := context.WithTimeout(ctx, tmout)
-	defer cancel()
-	spinCancel := spinner.Run(ctx, "Deleting "+r.String()+" ...")
-	err := r.delete(ctx)
-	if err != nil {
-		t := time.NewTicker(20 * time.Second)
-		defer t.Stop()
-	LOOPRETRY:
-		for {
-			select {
-			case <-ctx.Done():
-				break LOOPRETRY
-			case <-t.C:
-				err = r.delete(ctx)
-				if err == nil {
-					break LOOPRETRY
-				}
-			}
-		}
+func deleteInternetService(id, netID string) []graph.Call {
+	return []graph.Call{
+		{Call: "UnlinkInternetService", Params: osc.UnlinkInternetServiceRequest{InternetServiceId: id, NetId: netID}},
+		{Call: "DeleteInternetService", Params: osc.DeleteInternetServiceRequest{InternetServiceId: id}},
 	}
-	spinCancel()
-	if err != nil {
-		messages.ExitErr(fmt.Errorf("unable to delete %s in %s: %w", r, tmout, err))
-	}
-	messages.Success("%s was deleted in %s.", r, time.Since(start))
 }
 
-func onlyError[T any](_ T, err error) error {
-	if oerr := osc.AsErrorResponse(err); oerr != nil {
-		if osc.IsNotFound(err) {
-			return nil
-		}
-	}
-	return err
+func deleteNetPeering(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteNetPeering", Params: osc.DeleteNetPeeringRequest{NetPeeringId: id}}}
 }
 
-func deleteNet(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteNet(ctx, osc.DeleteNetRequest{NetId: id}))
-	}
+func deleteNetAccessPoint(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteNetAccessPoint", Params: osc.DeleteNetAccessPointRequest{NetAccessPointId: id}}}
 }
 
-func deleteSubnet(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteSubnet(ctx, osc.DeleteSubnetRequest{SubnetId: id}))
-	}
+func deleteNATService(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteNatService", Params: osc.DeleteNatServiceRequest{NatServiceId: id}}}
 }
 
-func deleteInternetService(cl *osc.Client, id, netID string) deleteFunc {
-	return func(ctx context.Context) error {
-		err := onlyError(cl.UnlinkInternetService(ctx, osc.UnlinkInternetServiceRequest{InternetServiceId: id, NetId: netID}))
-		if err != nil {
-			return err
-		}
-		return onlyError(cl.DeleteInternetService(ctx, osc.DeleteInternetServiceRequest{InternetServiceId: id}))
+func deleteRouteTable(id string, links []string) []graph.Call {
+	calls := make([]graph.Call, 0, len(links)+1)
+	for _, linkid := range links {
+		calls = append(calls, graph.Call{Call: "UnlinkRouteTable", Params: osc.UnlinkRouteTableRequest{LinkRouteTableId: linkid}})
 	}
+	return append(calls, graph.Call{Call: "DeleteRouteTable", Params: osc.DeleteRouteTableRequest{RouteTableId: id}})
 }
 
-func deleteNetPeering(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteNetPeering(ctx, osc.DeleteNetPeeringRequest{NetPeeringId: id}))
-	}
+func deleteLoadBalancer(name string) []graph.Call {
+	return []graph.Call{{Call: "DeleteLoadBalancer", Params: osc.DeleteLoadBalancerRequest{LoadBalancerName: name}}}
 }
 
-func deleteNetAccessPoint(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteNetAccessPoint(ctx, osc.DeleteNetAccessPointRequest{NetAccessPointId: id}))
-	}
+func deleteSecurityGroup(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteSecurityGroup", Params: osc.DeleteSecurityGroupRequest{SecurityGroupId: &id}}}
 }
 
-func deleteNATService(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteNatService(ctx, osc.DeleteNatServiceRequest{NatServiceId: id}))
+func deleteVirtualGateway(id, netID string) []graph.Call {
+	return []graph.Call{
+		{Call: "UnlinkVirtualGateway", Params: osc.UnlinkVirtualGatewayRequest{VirtualGatewayId: id, NetId: netID}},
+		{Call: "DeleteVirtualGateway", Params: osc.DeleteVirtualGatewayRequest{VirtualGatewayId: id}},
 	}
 }
 
-func deleteRouteTable(cl *osc.Client, id string, links []string) deleteFunc {
-	return func(ctx context.Context) error {
-		for _, linkid := range links {
-			err := onlyError(cl.UnlinkRouteTable(ctx, osc.UnlinkRouteTableRequest{LinkRouteTableId: linkid}))
-			if err != nil {
-				return err
-			}
-		}
-		return onlyError(cl.DeleteRouteTable(ctx, osc.DeleteRouteTableRequest{RouteTableId: id}))
-	}
+func deleteVpnConnection(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteVpnConnection", Params: osc.DeleteVpnConnectionRequest{VpnConnectionId: id}}}
 }
 
-func deleteLoadBalancer(cl *osc.Client, name string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteLoadBalancer(ctx, osc.DeleteLoadBalancerRequest{LoadBalancerName: name}))
-	}
+func deletePublicIP(id string) []graph.Call {
+	return []graph.Call{{Call: "DeletePublicIp", Params: osc.DeletePublicIpRequest{PublicIpId: &id}}}
 }
 
-func deleteSecurityGroup(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		err := onlyError(cl.DeleteSecurityGroup(ctx, osc.DeleteSecurityGroupRequest{SecurityGroupId: &id}))
-		if oerr := osc.AsErrorResponse(err); oerr != nil && oerr.GetCode() == "9051" {
-			return nil
-		}
-		return err
-	}
+func deleteVm(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteVms", Params: osc.DeleteVmsRequest{VmIds: []string{id}}}}
 }
 
-func deleteVirtualGateway(cl *osc.Client, id, netID string) deleteFunc {
-	return func(ctx context.Context) error {
-		err := onlyError(cl.UnlinkVirtualGateway(ctx, osc.UnlinkVirtualGatewayRequest{VirtualGatewayId: id, NetId: netID}))
-		if err != nil {
-			return err
-		}
-		return onlyError(cl.DeleteVirtualGateway(ctx, osc.DeleteVirtualGatewayRequest{VirtualGatewayId: id}))
+func deleteNIC(id, link string) []graph.Call {
+	var calls []graph.Call
+	if link != "" {
+		calls = append(calls, graph.Call{Call: "UnlinkNic", Params: osc.UnlinkNicRequest{LinkNicId: link}})
 	}
+	return append(calls, graph.Call{Call: "DeleteNic", Params: osc.DeleteNicRequest{NicId: id}})
 }
 
-func deleteVpnConnection(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteVpnConnection(ctx, osc.DeleteVpnConnectionRequest{VpnConnectionId: id}))
-	}
+func deleteVolume(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteVolume", Params: osc.DeleteVolumeRequest{VolumeId: id}}}
 }
 
-func deletePublicIP(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeletePublicIp(ctx, osc.DeletePublicIpRequest{PublicIpId: &id}))
-	}
+func deleteSnapshot(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteSnapshot", Params: osc.DeleteSnapshotRequest{SnapshotId: id}}}
 }
 
-func deleteVm(cl *osc.Client, id string) deleteFunc {
-	return func(ctx context.Context) error {
-		return onlyError(cl.DeleteVms(ctx, osc.DeleteVmsRequest{VmIds: []string{id}}))
-	}
+func deleteImage(id string) []graph.Call {
+	return []graph.Call{{Call: "DeleteImage", Params: osc.DeleteImageRequest{ImageId: id}}}
 }
 
-func deleteNIC(cl *osc.Client, id, link string) deleteFunc {
-	return func(ctx context.Context) error {
-		if link != "" {
-			err := onlyError(cl.UnlinkNic(ctx, osc.UnlinkNicRequest{LinkNicId: link}))
-			if err != nil {
-				return err
-			}
-		}
-		return onlyError(cl.DeleteNic(ctx, osc.DeleteNicRequest{NicId: id}))
-	}
+// iaasGoneCalls are the reads of the deleted IaaS resources, by type.
+var iaasGoneCalls = map[graph.Type]func(id string) goneCall{
+	Net: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadNets", Params: osc.ReadNetsRequest{Filters: &osc.FiltersNet{NetIds: &[]string{id}}}}, "Nets", "NetId"}
+	},
+	Subnet: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadSubnets", Params: osc.ReadSubnetsRequest{Filters: &osc.FiltersSubnet{SubnetIds: &[]string{id}}}}, "Subnets", "SubnetId"}
+	},
+	InternetService: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadInternetServices", Params: osc.ReadInternetServicesRequest{Filters: &osc.FiltersInternetService{InternetServiceIds: &[]string{id}}}}, "InternetServices", "InternetServiceId"}
+	},
+	NetPeering: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadNetPeerings", Params: osc.ReadNetPeeringsRequest{Filters: &osc.FiltersNetPeering{NetPeeringIds: &[]string{id}}}}, "NetPeerings", "NetPeeringId"}
+	},
+	VirtualGateway: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadVirtualGateways", Params: osc.ReadVirtualGatewaysRequest{Filters: &osc.FiltersVirtualGateway{VirtualGatewayIds: &[]string{id}}}}, "VirtualGateways", "VirtualGatewayId"}
+	},
+	VPNConnection: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadVpnConnections", Params: osc.ReadVpnConnectionsRequest{Filters: &osc.FiltersVpnConnection{VpnConnectionIds: &[]string{id}}}}, "VpnConnections", "VpnConnectionId"}
+	},
+	NATService: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadNatServices", Params: osc.ReadNatServicesRequest{Filters: &osc.FiltersNatService{NatServiceIds: &[]string{id}}}}, "NatServices", "NatServiceId"}
+	},
+	RouteTable: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadRouteTables", Params: osc.ReadRouteTablesRequest{Filters: &osc.FiltersRouteTable{RouteTableIds: &[]string{id}}}}, "RouteTables", "RouteTableId"}
+	},
+	SecurityGroup: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadSecurityGroups", Params: osc.ReadSecurityGroupsRequest{Filters: &osc.FiltersSecurityGroup{SecurityGroupIds: &[]string{id}}}}, "SecurityGroups", "SecurityGroupId"}
+	},
+	LoadBalancer: func(name string) goneCall {
+		return goneCall{graph.Call{Call: "ReadLoadBalancers", Params: osc.ReadLoadBalancersRequest{Filters: &osc.FiltersLoadBalancer{LoadBalancerNames: &[]string{name}}}}, "LoadBalancers", "LoadBalancerName"}
+	},
+	NetAccessPoint: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadNetAccessPoints", Params: osc.ReadNetAccessPointsRequest{Filters: &osc.FiltersNetAccessPoint{NetAccessPointIds: &[]string{id}}}}, "NetAccessPoints", "NetAccessPointId"}
+	},
+	PublicIP: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadPublicIps", Params: osc.ReadPublicIpsRequest{Filters: &osc.FiltersPublicIp{PublicIpIds: &[]string{id}}}}, "PublicIps", "PublicIpId"}
+	},
+	VM: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadVms", Params: osc.ReadVmsRequest{Filters: &osc.FiltersVm{VmIds: &[]string{id}}}}, "Vms", "VmId"}
+	},
+	NIC: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadNics", Params: osc.ReadNicsRequest{Filters: &osc.FiltersNic{NicIds: &[]string{id}}}}, "Nics", "NicId"}
+	},
+	Volume: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadVolumes", Params: osc.ReadVolumesRequest{Filters: &osc.FiltersVolume{VolumeIds: &[]string{id}}}}, "Volumes", "VolumeId"}
+	},
+	Snapshot: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadSnapshots", Params: osc.ReadSnapshotsRequest{Filters: &osc.FiltersSnapshot{SnapshotIds: &[]string{id}}}}, "Snapshots", "SnapshotId"}
+	},
+	Image: func(id string) goneCall {
+		return goneCall{graph.Call{Call: "ReadImages", Params: osc.ReadImagesRequest{Filters: &osc.FiltersImage{ImageIds: &[]string{id}}}}, "Images", "ImageId"}
+	},
 }
@@ -0,0 +1,370 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/flags"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/iso8601"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Finds resources which are no longer used, and optionally deletes them",
+	Long: `Finds resources which are no longer used, and optionally deletes them.
+
+Categories are:
+* stoppedvm: stopped VMs - the API has no stop date, the threshold applies to the creation date of the VM,
+* image: images of the account not used by any VM,
+* snapshot: snapshots of the account whose volume has been deleted, and which are not used by an image - snapshots having
+  no source volume in the region, e.g. imported or copied from another region, are ignored,
+* volume: volumes not attached to any VM,
+* publicip: public IPs not linked to any VM or NIC.
+
+Resources are only reported if they were created before the age threshold of their category, set by --rule category=offset,
+offsets using the same syntax as time flags (e.g. -7d, -1mo, -1y, or a date). An empty offset disables the threshold.
+Public IPs have no creation date, and no threshold.
+
+With --delete, the orphaned resources are deleted after confirmation.`,
+	Example: `octl iaas orphans
+octl iaas orphans --category volume,snapshot --rule volume=-1mo
+octl iaas orphans --category stoppedvm --rule stoppedvm=-1y
+octl iaas orphans --category publicip --delete`,
+	Args: cobra.NoArgs,
+	Run:  orphans,
+}
+
+func init() {
+	orphansCmd.Flags().StringSlice("category", nil, "categories of resources to find (stoppedvm, image, snapshot, volume, publicip) - all by default")
+	orphansCmd.Flags().StringSlice("rule", nil, "age thresholds, in the category=offset format (default stoppedvm=-3mo,image=-3mo,snapshot=-1mo,volume=-7d)")
+	orphansCmd.Flags().Bool("delete", false, "delete the orphaned resources")
+	orphansCmd.Flags().Int(alias.ParallelFlag, 8, "maximum number of resources deleted concurrently")
+	orphansCmd.Flags().Duration("timeout", 10*time.Minute, "Timeout for a single resource deletion")
+}
+
+// orphanCategory is a category of orphaned resources.
+type orphanCategory struct {
+	name string
+	typ  graph.Type
+	// phase is the deletion phase, VMs being deleted before the images they use.
+	phase int
+	// age is the default age threshold, empty if resources of the category have no creation date.
+	age string
+}
+
+var orphanCategories = []orphanCategory{
+	{name: "stoppedvm", typ: VM, phase: 1, age: "-3mo"},
+	{name: "image", typ: Image, phase: 2, age: "-3mo"},
+	{name: "snapshot", typ: Snapshot, phase: 3, age: "-1mo"},
+	{name: "volume", typ: Volume, phase: 3, age: "-7d"},
+	{name: "publicip", typ: PublicIP, phase: 3},
+}
+
+// orphan is a resource which is no longer used.
+type orphan struct {
+	Category     string `json:"Category"`
+	ID           string `json:"Id"`
+	Name         string `json:"Name,omitempty"`
+	CreationDate string `json:"CreationDate,omitempty"`
+	Reason       string `json:"Reason"`
+}
+
+var orphanColumns = config.Columns{
+	{Title: "Category", Content: ".Category"},
+	{Title: "ID", Content: ".Id"},
+	{Title: "Name", Content: ".Name"},
+	{Title: "Created", Content: ".CreationDate"},
+	{Title: "Reason", Content: ".Reason"},
+}
+
+// orphanRules returns the age threshold of the selected categories, a nil threshold matching all resources.
+func orphanRules(cmd *cobra.Command) (map[string]*iso8601.Time, error) {
+	names, _ := cmd.Flags().GetStringSlice("category")
+	if len(names) == 0 {
+		names = lo.Map(orphanCategories, func(c orphanCategory, _ int) string { return c.name })
+	}
+	offsets := map[string]string{}
+	for _, c := range orphanCategories {
+		offsets[c.name] = c.age
+	}
+	rules, _ := cmd.Flags().GetStringSlice("rule")
+	for _, r := range rules {
+		name, offset, found := strings.Cut(r, "=")
+		if _, ok := offsets[name]; !found || !ok {
+			return nil, fmt.Errorf("invalid rule %q, expecting category=offset", r)
+		}
+		if name == "publicip" && offset != "" {
+			return nil, fmt.Errorf("invalid rule %q, public IPs have no creation date", r)
+		}
+		offsets[name] = offset
+	}
+	thresholds := map[string]*iso8601.Time{}
+	for _, name := range names {
+		offset, ok := offsets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q", name)
+		}
+		if offset == "" {
+			thresholds[name] = nil
+			continue
+		}
+		v := flags.NewTimeValue()
+		if err := v.Set(offset); err != nil {
+			return nil, fmt.Errorf("rule for %s: %w", name, err)
+		}
+		t, _ := v.Value()
+		thresholds[name] = &t
+	}
+	return thresholds, nil
+}
+
+// orphanTagged holds the tags of a resource, the Name tag naming the resource.
+type orphanTagged struct {
+	Tags []osc.ResourceTag
+}
+
+func (r orphanTagged) name() string {
+	tag, _ := lo.Find(r.Tags, func(t osc.ResourceTag) bool { return t.Key == "Name" })
+	return tag.Value
+}
+
+// orphanResources are the resources read to find orphans, as returned by the API.
+type orphanResources struct {
+	Vms []struct {
+		orphanTagged
+		VmId         string
+		State        string
+		ImageId      string
+		CreationDate iso8601.Time
+	}
+	Images []struct {
+		ImageId             string
+		ImageName           string
+		AccountId           string
+		CreationDate        iso8601.Time
+		BlockDeviceMappings []struct {
+			Bsu struct {
+				SnapshotId string
+			}
+		}
+	}
+	Snapshots []struct {
+		orphanTagged
+		SnapshotId   string
+		VolumeId     string
+		AccountId    string
+		CreationDate iso8601.Time
+	}
+	Volumes []struct {
+		orphanTagged
+		VolumeId     string
+		State        string
+		CreationDate iso8601.Time
+	}
+	PublicIps []struct {
+		orphanTagged
+		PublicIpId     string
+		PublicIp       string
+		LinkPublicIpId string
+	}
+}
+
+// noSourceVolumes are the placeholder volume IDs of snapshots having no source volume in the region.
+var noSourceVolumes = map[string]bool{"vol-00000000": true, "vol-ffffffff": true}
+
+// findOrphans returns the orphaned resources of the categories having a threshold.
+func findOrphans(ctx context.Context, cl *osc.Client, thresholds map[string]*iso8601.Time) ([]orphan, error) {
+	accounts, err := readAll(ctx, cl, inventoryCall{entity: "Accounts", call: "ReadAccounts", content: "Accounts"})
+	if err != nil {
+		return nil, fmt.Errorf("ReadAccounts: %w", err)
+	}
+	var account []struct{ AccountId string }
+	if err := fromJSON(accounts, &account); err != nil {
+		return nil, err
+	}
+	// without the account, public images and snapshots shared with the account cannot be told apart from its own
+	if len(account) == 0 || account[0].AccountId == "" {
+		return nil, errors.New("ReadAccounts: the account of the profile cannot be read")
+	}
+	accountIDs := &[]string{account[0].AccountId}
+	calls := []inventoryCall{
+		{entity: "Vms", call: "ReadVms", content: "Vms"},
+		{entity: "Images", call: "ReadImages", content: "Images", request: osc.ReadImagesRequest{Filters: &osc.FiltersImage{AccountIds: accountIDs}}},
+		{entity: "Snapshots", call: "ReadSnapshots", content: "Snapshots", request: osc.ReadSnapshotsRequest{Filters: &osc.FiltersSnapshot{AccountIds: accountIDs}}},
+		{entity: "Volumes", call: "ReadVolumes", content: "Volumes"},
+		{entity: "PublicIps", call: "ReadPublicIps", content: "PublicIps"},
+	}
+	inv, failed := readInventory(ctx, cl, calls, len(calls))
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%s: %s", failed[0].Call, failed[0].Error)
+	}
+	var res orphanResources
+	if err := fromJSON(inv, &res); err != nil {
+		return nil, err
+	}
+	owned := func(accountID string) bool {
+		return accountID == account[0].AccountId
+	}
+	old := func(category string, created iso8601.Time) bool {
+		threshold, found := thresholds[category]
+		if !found {
+			return false
+		}
+		return threshold == nil || (!created.IsZero() && created.Before(threshold.Time))
+	}
+	date := func(t iso8601.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.String()
+	}
+
+	orphans := []orphan{}
+	usedImages := map[string]bool{}
+	for _, vm := range res.Vms {
+		usedImages[vm.ImageId] = true
+		if vm.State == "stopped" && old("stoppedvm", vm.CreationDate) {
+			orphans = append(orphans, orphan{Category: "stoppedvm", ID: vm.VmId, Name: vm.name(), CreationDate: date(vm.CreationDate), Reason: "stopped"})
+		}
+	}
+	usedSnapshots := map[string]bool{}
+	for _, img := range res.Images {
+		for _, bdm := range img.BlockDeviceMappings {
+			usedSnapshots[bdm.Bsu.SnapshotId] = true
+		}
+		if owned(img.AccountId) && !usedImages[img.ImageId] && old("image", img.CreationDate) {
+			orphans = append(orphans, orphan{Category: "image", ID: img.ImageId, Name: img.ImageName, CreationDate: date(img.CreationDate), Reason: "not used by any VM"})
+		}
+	}
+	volumes := map[string]bool{}
+	for _, vol := range res.Volumes {
+		volumes[vol.VolumeId] = true
+		if vol.State == "available" && old("volume", vol.CreationDate) {
+			orphans = append(orphans, orphan{Category: "volume", ID: vol.VolumeId, Name: vol.name(), CreationDate: date(vol.CreationDate), Reason: "not attached"})
+		}
+	}
+	for _, snap := range res.Snapshots {
+		// imported snapshots, and snapshots copied from another region, have no source volume in the region
+		if snap.VolumeId == "" || noSourceVolumes[snap.VolumeId] {
+			continue
+		}
+		if owned(snap.AccountId) && !volumes[snap.VolumeId] && !usedSnapshots[snap.SnapshotId] && old("snapshot", snap.CreationDate) {
+			orphans = append(orphans, orphan{
+				Category: "snapshot", ID: snap.SnapshotId, Name: snap.name(), CreationDate: date(snap.CreationDate),
+				Reason: fmt.Sprintf("volume %s deleted", snap.VolumeId),
+			})
+		}
+	}
+	if _, found := thresholds["publicip"]; found {
+		for _, pip := range res.PublicIps {
+			if pip.LinkPublicIpId == "" {
+				orphans = append(orphans, orphan{Category: "publicip", ID: pip.PublicIpId, Name: lo.CoalesceOrEmpty(pip.name(), pip.PublicIp), Reason: "not linked"})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// readOrphans finds the orphaned resources selected by the flags.
+func readOrphans(cmd *cobra.Command) (*osc.Client, []orphan) {
+	thresholds, err := orphanRules(cmd)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cancel := spinner.Run(cmd.Context(), "Finding orphaned resources...")
+	found, err := findOrphans(cmd.Context(), cl, thresholds)
+	cancel()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	return cl, found
+}
+
+func orphans(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	cl, found := readOrphans(cmd)
+	display := func(cmd *cobra.Command, args []string) {
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", orphanColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(found))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	if del, _ := cmd.Flags().GetBool("delete"); !del {
+		display(cmd, args)
+		return
+	}
+	if len(found) == 0 {
+		messages.Info("No orphaned resource found.")
+		return
+	}
+	// the deleted resources are the ones displayed, even if some have changed since
+	alias.Confirm(config.ActionDelete, display, func(cmd *cobra.Command, args []string) {
+		deleteOrphans(cmd, cl, found)
+	})(cmd, args)
+}
+
+// deleteOrphans deletes orphaned resources, in the order of the phases of their categories.
+func deleteOrphans(cmd *cobra.Command, cl *osc.Client, found []orphan) {
+	categories := lo.KeyBy(orphanCategories, func(c orphanCategory) string { return c.name })
+	plan := &graph.Plan{Service: "iaas", Steps: lo.Map(found, func(o orphan, _ int) graph.Step {
+		c := categories[o.Category]
+		var calls []graph.Call
+		switch c.typ {
+		case VM:
+			calls = deleteVm(o.ID)
+		case Image:
+			calls = deleteImage(o.ID)
+		case Snapshot:
+			calls = deleteSnapshot(o.ID)
+		case Volume:
+			calls = deleteVolume(o.ID)
+		case PublicIP:
+			calls = deletePublicIP(o.ID)
+		}
+		return graph.Step{Phase: c.phase, Type: c.typ, ID: o.ID, Name: o.Name, Calls: calls}
+	})}
+
+	tmout, _ := cmd.Flags().GetDuration("timeout")
+	parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+	var failed []teardownResult
+	for _, phase := range plan.Phases() {
+		if len(phase) > 0 {
+			failed = append(failed, deletePhase(cmd.Context(), cl, nil, phase, max(parallel, 1), tmout)...)
+		}
+	}
+	if len(failed) == 0 {
+		return
+	}
+	tbl := format.Tabular{Columns: teardownColumns, Formatter: format.TableFormatter{}}
+	if err := tbl.Format(cmd.Context(), os.Stderr, failed); err != nil {
+		messages.ExitErr(err)
+	}
+	messages.Exit(1, "%d of %d resource(s) not deleted", len(failed), len(plan.Steps))
+}
@@ -0,0 +1,102 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+)
+
+// resourceUsers are the VMs, NICs and load balancers using the security groups and the public IPs of an account.
+type resourceUsers struct {
+	securityGroups map[string][]string
+	publicIPs      map[string][]string
+}
+
+// userResources are the resources read to find the users of security groups and public IPs, as returned by the API.
+type userResources struct {
+	Vms []struct {
+		VmId           string
+		PublicIp       string
+		SecurityGroups []struct {
+			SecurityGroupId string
+		}
+	}
+	Nics []struct {
+		NicId   string
+		LinkNic struct {
+			VmId string
+		}
+		LinkPublicIp struct {
+			PublicIp string
+		}
+		SecurityGroups []struct {
+			SecurityGroupId string
+		}
+	}
+	LoadBalancers []struct {
+		LoadBalancerName string
+		PublicIp         string
+		SecurityGroups   []string
+	}
+}
+
+// readResourceUsers reads the users of the security groups and the public IPs of the account.
+// A NIC linked to a VM is reported as used by the VM.
+func readResourceUsers(ctx context.Context, cl *osc.Client) (*resourceUsers, error) {
+	calls := []inventoryCall{
+		{entity: "Vms", call: "ReadVms", content: "Vms"},
+		{entity: "Nics", call: "ReadNics", content: "Nics"},
+		{entity: "LoadBalancers", call: "ReadLoadBalancers", content: "LoadBalancers"},
+	}
+	inv, failed := readInventory(ctx, cl, calls, len(calls))
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%s: %s", failed[0].Call, failed[0].Error)
+	}
+	var res userResources
+	if err := fromJSON(inv, &res); err != nil {
+		return nil, err
+	}
+	u := &resourceUsers{securityGroups: map[string][]string{}, publicIPs: map[string][]string{}}
+	add := func(users map[string][]string, id, user string) {
+		if id != "" && !lo.Contains(users[id], user) {
+			users[id] = append(users[id], user)
+		}
+	}
+	for _, vm := range res.Vms {
+		add(u.publicIPs, vm.PublicIp, vm.VmId)
+		for _, sg := range vm.SecurityGroups {
+			add(u.securityGroups, sg.SecurityGroupId, vm.VmId)
+		}
+	}
+	for _, nic := range res.Nics {
+		user := lo.CoalesceOrEmpty(nic.LinkNic.VmId, nic.NicId)
+		add(u.publicIPs, nic.LinkPublicIp.PublicIp, user)
+		for _, sg := range nic.SecurityGroups {
+			add(u.securityGroups, sg.SecurityGroupId, user)
+		}
+	}
+	for _, lbu := range res.LoadBalancers {
+		add(u.publicIPs, lbu.PublicIp, lbu.LoadBalancerName)
+		for _, sg := range lbu.SecurityGroups {
+			add(u.securityGroups, sg, lbu.LoadBalancerName)
+		}
+	}
+	return u, nil
+}
+
+// onlyUsedBy returns the calls if the resource has no other user than owner, or no call if it is shared.
+// A shared resource is kept in the graph, but is not deleted.
+func onlyUsedBy(users []string, owner string, calls []graph.Call) []graph.Call {
+	if lo.EveryBy(users, func(user string) bool { return user == owner }) {
+		return calls
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+)
+
+// sgRule is a security group rule, as returned by the API.
+type sgRule struct {
+	IpProtocol            string     `json:"IpProtocol"`
+	FromPortRange         int        `json:"FromPortRange"`
+	ToPortRange           int        `json:"ToPortRange"`
+	IpRanges              []string   `json:"IpRanges,omitempty"`
+	SecurityGroupsMembers []sgMember `json:"SecurityGroupsMembers,omitempty"`
+}
+
+// sgMember is a security group referenced by a rule.
+type sgMember struct {
+	AccountId         string `json:"AccountId,omitempty"`
+	SecurityGroupId   string `json:"SecurityGroupId,omitempty"`
+	SecurityGroupName string `json:"SecurityGroupName,omitempty"`
+}
+
+func (m sgMember) String() string {
+	return lo.CoalesceOrEmpty(m.SecurityGroupId, m.SecurityGroupName)
+}
+
+// allPorts returns true if the rule allows all ports.
+func (r sgRule) allPorts() bool {
+	return r.IpProtocol == "-1" || (r.FromPortRange <= 1 && r.ToPortRange >= 65535)
+}
+
+func (r sgRule) String() string {
+	proto := r.IpProtocol
+	ports := strconv.Itoa(r.FromPortRange)
+	switch {
+	case r.IpProtocol == "-1":
+		proto, ports = "all", "all"
+	case r.allPorts():
+		ports = "all"
+	case r.ToPortRange != r.FromPortRange:
+		ports += "-" + strconv.Itoa(r.ToPortRange)
+	}
+	sources := append(slices.Clone(r.IpRanges), lo.Map(r.SecurityGroupsMembers, func(m sgMember, _ int) string { return m.String() })...)
+	return fmt.Sprintf("%s %s from %s", proto, ports, strings.Join(sources, ","))
+}
+
+// securityGroup is a security group, as returned by the API.
+type securityGroup struct {
+	AccountId         string
+	SecurityGroupId   string
+	SecurityGroupName string
+	InboundRules      []sgRule
+	OutboundRules     []sgRule
+}
+
+// readSecurityGroup reads a security group.
+func readSecurityGroup(ctx context.Context, cl *osc.Client, id string) (*securityGroup, error) {
+	resp, err := runner.CallJSON(ctx, cl, "ReadSecurityGroups", map[string]any{"Filters": map[string]any{"SecurityGroupIds": []string{id}}})
+	if err != nil {
+		return nil, fmt.Errorf("read security group: %w", err)
+	}
+	var sgs []securityGroup
+	if err := fromJSON(resp["SecurityGroups"], &sgs); err != nil {
+		return nil, err
+	}
+	if len(sgs) == 0 {
+		return nil, fmt.Errorf("security group %s not found", id)
+	}
+	return &sgs[0], nil
+}
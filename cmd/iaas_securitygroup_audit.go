@@ -0,0 +1,223 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var securityGroupAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Reports risky security group rules, and security groups attached to nothing",
+	Long: `Reports risky security group rules, and security groups attached to nothing.
+
+* high: inbound rules open to the world on an administration or database port (SSH, RDP, MySQL, PostgreSQL, ...), or on all ports,
+* medium: inbound rules on all ports, and rules referencing security groups which no longer exist,
+* low: security groups not used by any VM, NIC or load balancer.
+
+With --fail-on, the command fails if a finding has at least this severity, e.g. in a CI pipeline.`,
+	Example: `octl iaas securitygroup audit
+octl iaas securitygroup audit -o json --fail-on high`,
+	Args: cobra.NoArgs,
+	Run:  auditSecurityGroups,
+}
+
+func init() {
+	securityGroupAuditCmd.Flags().String("fail-on", "", "fail if a finding has at least this severity (low, medium or high)")
+}
+
+// severities are ordered by increasing severity.
+var severities = []string{"low", "medium", "high"}
+
+// sensitivePorts are the ports which should not be reachable from anywhere.
+var sensitivePorts = map[int]string{
+	22:    "SSH",
+	1433:  "SQL Server",
+	1521:  "Oracle",
+	3306:  "MySQL",
+	3389:  "RDP",
+	5432:  "PostgreSQL",
+	6379:  "Redis",
+	9200:  "Elasticsearch",
+	27017: "MongoDB",
+}
+
+// finding is a risk found in a security group.
+type finding struct {
+	Severity          string `json:"Severity"`
+	SecurityGroupId   string `json:"SecurityGroupId"`
+	SecurityGroupName string `json:"SecurityGroupName"`
+	Rule              string `json:"Rule,omitempty"`
+	Finding           string `json:"Finding"`
+}
+
+var findingColumns = config.Columns{
+	{Title: "Severity", Content: ".Severity"},
+	{Title: "ID", Content: ".SecurityGroupId"},
+	{Title: "Name", Content: ".SecurityGroupName"},
+	{Title: "Rule", Content: ".Rule"},
+	{Title: "Finding", Content: ".Finding"},
+}
+
+func auditSecurityGroups(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	failOn, _ := cmd.Flags().GetString("fail-on")
+	if failOn != "" && !slices.Contains(severities, failOn) {
+		messages.ExitErr(fmt.Errorf("invalid severity %q, use one of %s", failOn, strings.Join(severities, ", ")))
+	}
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cancel := spinner.Run(cmd.Context(), "Auditing security groups...")
+	findings, err := auditAll(cmd.Context(), cl)
+	cancel()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", findingColumns, false, false)
+	if err == nil {
+		err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(findings))
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if failOn == "" {
+		return
+	}
+	threshold := slices.Index(severities, failOn)
+	if n := lo.CountBy(findings, func(f finding) bool { return slices.Index(severities, f.Severity) >= threshold }); n > 0 {
+		messages.Exit(1, "%d finding(s) with a %s severity or higher", n, failOn)
+	}
+}
+
+// auditAll reads all security groups and the resources using them, and returns all findings, the most severe first.
+func auditAll(ctx context.Context, cl *osc.Client) ([]finding, error) {
+	calls := []inventoryCall{
+		{entity: "securitygroup", call: "ReadSecurityGroups", content: "SecurityGroups"},
+		{entity: "vm", call: "ReadVms", content: "Vms"},
+		{entity: "nic", call: "ReadNics", content: "Nics"},
+		{entity: "loadbalancer", call: "ReadLoadBalancers", content: "LoadBalancers"},
+	}
+	inv, failed := readInventory(ctx, cl, calls, len(calls))
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%s: %s", failed[0].Call, failed[0].Error)
+	}
+	var (
+		sgs  []securityGroup
+		used struct {
+			Vms []struct {
+				SecurityGroups []struct{ SecurityGroupId string }
+			}
+			Nics []struct {
+				SecurityGroups []struct{ SecurityGroupId string }
+			}
+			LoadBalancers []struct{ SecurityGroups []string }
+		}
+	)
+	if err := fromJSON(inv["securitygroup"], &sgs); err != nil {
+		return nil, err
+	}
+	if err := fromJSON(map[string]any{"Vms": inv["vm"], "Nics": inv["nic"], "LoadBalancers": inv["loadbalancer"]}, &used); err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+	for _, vm := range used.Vms {
+		for _, sg := range vm.SecurityGroups {
+			inUse[sg.SecurityGroupId] = true
+		}
+	}
+	for _, nic := range used.Nics {
+		for _, sg := range nic.SecurityGroups {
+			inUse[sg.SecurityGroupId] = true
+		}
+	}
+	for _, lbu := range used.LoadBalancers {
+		for _, id := range lbu.SecurityGroups {
+			inUse[id] = true
+		}
+	}
+	exists := lo.SliceToMap(sgs, func(sg securityGroup) (string, bool) { return sg.SecurityGroupId, true })
+
+	findings := []finding{}
+	for _, sg := range sgs {
+		findings = append(findings, auditGroup(sg, exists, inUse)...)
+	}
+	slices.SortStableFunc(findings, func(a, b finding) int {
+		return slices.Index(severities, b.Severity) - slices.Index(severities, a.Severity)
+	})
+	return findings, nil
+}
+
+// auditGroup returns the findings of a security group.
+func auditGroup(sg securityGroup, exists, inUse map[string]bool) []finding {
+	var findings []finding
+	add := func(severity string, rule *sgRule, msg string) {
+		f := finding{Severity: severity, SecurityGroupId: sg.SecurityGroupId, SecurityGroupName: sg.SecurityGroupName, Finding: msg}
+		if rule != nil {
+			f.Rule = rule.String()
+		}
+		findings = append(findings, f)
+	}
+	for _, r := range sg.InboundRules {
+		open := slices.ContainsFunc(r.IpRanges, func(ipr string) bool { return ipr == "0.0.0.0/0" || ipr == "::/0" })
+		switch {
+		case r.allPorts() && open:
+			add("high", &r, "all ports open to the world")
+		case r.allPorts() && len(r.IpRanges) > 0:
+			// rules only allowing security groups, e.g. the self-referencing rule of default groups, are not reported
+			add("medium", &r, "all ports open")
+		case open && r.IpProtocol != "icmp":
+			for _, port := range sortedPorts(r) {
+				add("high", &r, fmt.Sprintf("%s port %d open to the world", sensitivePorts[port], port))
+			}
+		}
+	}
+	for _, r := range slices.Concat(sg.InboundRules, sg.OutboundRules) {
+		for _, m := range r.SecurityGroupsMembers {
+			if (m.AccountId == "" || m.AccountId == sg.AccountId) && !exists[m.SecurityGroupId] {
+				add("medium", &r, fmt.Sprintf("references %s, which no longer exists", m.SecurityGroupId))
+			}
+		}
+	}
+	if !inUse[sg.SecurityGroupId] && sg.SecurityGroupName != "default" {
+		add("low", nil, "not used by any VM, NIC or load balancer")
+	}
+	return findings
+}
+
+// sortedPorts returns the sensitive ports allowed by a rule.
+func sortedPorts(r sgRule) []int {
+	ports := lo.Filter(lo.Keys(sensitivePorts), func(p int, _ int) bool { return p >= r.FromPortRange && p <= r.ToPortRange })
+	slices.Sort(ports)
+	return ports
+}
+
+// fromJSON converts a value to another type, using its JSON representation.
+func fromJSON(v, to any) error {
+	buf, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(buf, to)
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected content: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/diff"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var securityGroupSyncCmd = &cobra.Command{
+	Use:   "sync security_group_id -f rules.yaml",
+	Short: "Updates the rules of a security group to match a file",
+	Long: `Updates the rules of a security group to match a file.
+
+The rules to create and to delete are displayed as a diff, and applied after confirmation. Rules are compared source by source,
+only the missing sources being added and the extra sources being removed. If InboundRules or OutboundRules is missing from
+the file, the rules of this flow are left unchanged, an empty list deleting all of them.
+
+Example of file, as written by octl iaas securitygroup export:
+
+  InboundRules:
+    - IpProtocol: tcp
+      FromPortRange: 443
+      ToPortRange: 443
+      IpRanges:
+        - 0.0.0.0/0
+    - IpProtocol: tcp
+      FromPortRange: 22
+      ToPortRange: 22
+      SecurityGroupsMembers:
+        - SecurityGroupId: sg-12345678
+  OutboundRules:
+    - IpProtocol: "-1"
+      FromPortRange: -1
+      ToPortRange: -1
+      IpRanges:
+        - 0.0.0.0/0`,
+	Example: `octl iaas securitygroup export sg-12345678 > rules.yaml
+octl iaas securitygroup sync sg-12345678 -f rules.yaml --plan`,
+	Args: cobra.ExactArgs(1),
+	Run:  syncSecurityGroup,
+}
+
+var securityGroupExportCmd = &cobra.Command{
+	Use:     "export security_group_id",
+	Short:   "Exports the rules of a security group, in the format used by sync",
+	Example: "octl iaas securitygroup export sg-12345678 > rules.yaml",
+	Args:    cobra.ExactArgs(1),
+	Run:     exportSecurityGroup,
+}
+
+func init() {
+	securityGroupSyncCmd.Flags().StringP("file", "f", "", "file describing the rules of the security group")
+	securityGroupSyncCmd.Flags().Bool(runner.PlanFlag, false, "display the rules that would be created and deleted, without changing them")
+	_ = securityGroupSyncCmd.MarkFlagRequired("file")
+}
+
+// sgRuleSet is the set of rules of a security group.
+type sgRuleSet struct {
+	InboundRules  []sgRule `json:"InboundRules"`
+	OutboundRules []sgRule `json:"OutboundRules"`
+}
+
+// sgPermission is a rule allowing a single source, i.e. an IP range or a security group.
+type sgPermission struct {
+	flow       string
+	protocol   string
+	from, to   int
+	ipRange    string
+	member     string
+	memberSpec sgMember
+}
+
+// key returns the fields identifying a permission, the account of a member being ignored.
+func (p sgPermission) key() sgPermission {
+	p.memberSpec = sgMember{}
+	return p
+}
+
+// permissions splits rules into permissions.
+func permissions(flow string, rules []sgRule) []sgPermission {
+	var perms []sgPermission
+	for _, r := range rules {
+		p := sgPermission{flow: flow, protocol: strings.ToLower(r.IpProtocol), from: r.FromPortRange, to: r.ToPortRange}
+		if p.protocol == "-1" {
+			p.from, p.to = -1, -1
+		}
+		for _, ipr := range r.IpRanges {
+			p := p
+			p.ipRange = ipr
+			perms = append(perms, p)
+		}
+		for _, m := range r.SecurityGroupsMembers {
+			p := p
+			p.member, p.memberSpec = m.String(), m
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+// rules groups permissions of a flow into rules, permissions having the same protocol and ports being merged.
+func rules(flow string, perms []sgPermission) []sgRule {
+	var res []sgRule
+	for _, p := range perms {
+		if p.flow != flow {
+			continue
+		}
+		idx := slices.IndexFunc(res, func(r sgRule) bool {
+			return r.IpProtocol == p.protocol && r.FromPortRange == p.from && r.ToPortRange == p.to
+		})
+		if idx < 0 {
+			res = append(res, sgRule{IpProtocol: p.protocol, FromPortRange: p.from, ToPortRange: p.to})
+			idx = len(res) - 1
+		}
+		if p.ipRange != "" {
+			res[idx].IpRanges = append(res[idx].IpRanges, p.ipRange)
+		} else {
+			res[idx].SecurityGroupsMembers = append(res[idx].SecurityGroupsMembers, p.memberSpec)
+		}
+	}
+	return res
+}
+
+// sgChanges are the permissions to add to and to remove from a security group.
+type sgChanges struct {
+	added, removed []sgPermission
+}
+
+// compareRules returns the changes needed for current rules to match wanted rules.
+func compareRules(current, wanted sgRuleSet) sgChanges {
+	cur := slices.Concat(permissions("Inbound", current.InboundRules), permissions("Outbound", current.OutboundRules))
+	want := slices.Concat(permissions("Inbound", wanted.InboundRules), permissions("Outbound", wanted.OutboundRules))
+	has := func(perms []sgPermission) func(p sgPermission) bool {
+		keys := lo.SliceToMap(perms, func(p sgPermission) (sgPermission, bool) { return p.key(), true })
+		return func(p sgPermission) bool { return keys[p.key()] }
+	}
+	inCur, inWant := has(cur), has(want)
+	changes := sgChanges{
+		added:   lo.UniqBy(lo.Reject(want, func(p sgPermission, _ int) bool { return inCur(p) }), sgPermission.key),
+		removed: lo.UniqBy(lo.Reject(cur, func(p sgPermission, _ int) bool { return inWant(p) }), sgPermission.key),
+	}
+	for _, perms := range [][]sgPermission{changes.added, changes.removed} {
+		slices.SortStableFunc(perms, func(a, b sgPermission) int {
+			return cmp.Or(cmp.Compare(a.flow, b.flow), cmp.Compare(a.protocol, b.protocol), cmp.Compare(a.from, b.from), cmp.Compare(a.to, b.to))
+		})
+	}
+	return changes
+}
+
+// write writes the rules to delete and to create, as a diff.
+func (c sgChanges) write() error {
+	var changes []diff.Change
+	for _, flow := range []string{"Inbound", "Outbound"} {
+		for _, r := range rules(flow, c.removed) {
+			changes = append(changes, diff.Change{Path: flow + "Rules", Kind: diff.Removed, Current: r.String()})
+		}
+		for _, r := range rules(flow, c.added) {
+			changes = append(changes, diff.Change{Path: flow + "Rules", Kind: diff.Added, Requested: r.String()})
+		}
+	}
+	return diff.Write(os.Stdout, changes)
+}
+
+// apply creates the missing rules, then deletes the extra rules.
+func (c sgChanges) apply(ctx context.Context, cl *osc.Client, id string) error {
+	for _, step := range []struct {
+		call  string
+		perms []sgPermission
+	}{{"CreateSecurityGroupRule", c.added}, {"DeleteSecurityGroupRule", c.removed}} {
+		for _, flow := range []string{"Inbound", "Outbound"} {
+			rs := rules(flow, step.perms)
+			if len(rs) == 0 {
+				continue
+			}
+			req := map[string]any{"SecurityGroupId": id, "Flow": flow, "Rules": rs}
+			if _, err := runner.CallJSON(ctx, cl, step.call, req); err != nil {
+				return fmt.Errorf("%s: %w", step.call, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadRuleSet loads the rules of a security group, written in JSON or YAML.
+func loadRuleSet(path string) (sgRuleSet, error) {
+	var rs sgRuleSet
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return rs, fmt.Errorf("read rules: %w", err)
+	}
+	if err := yaml.UnmarshalWithOptions(buf, &rs, yaml.Strict()); err != nil {
+		return rs, fmt.Errorf("read rules %s: %w", path, err)
+	}
+	return rs, nil
+}
+
+func syncSecurityGroup(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	path, _ := cmd.Flags().GetString("file")
+	wanted, err := loadRuleSet(path)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	sg, err := readSecurityGroup(cmd.Context(), cl, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	// a flow missing from the file is left unchanged
+	current := sgRuleSet{InboundRules: sg.InboundRules, OutboundRules: sg.OutboundRules}
+	if wanted.InboundRules == nil {
+		wanted.InboundRules = current.InboundRules
+	}
+	if wanted.OutboundRules == nil {
+		wanted.OutboundRules = current.OutboundRules
+	}
+	changes := compareRules(current, wanted)
+	if len(changes.added) == 0 && len(changes.removed) == 0 {
+		messages.Info("The rules of %s are up to date.", args[0])
+		return
+	}
+	display := func(cmd *cobra.Command, args []string) {
+		if err := changes.write(); err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	if planned, _ := cmd.Flags().GetBool(runner.PlanFlag); planned {
+		display(cmd, args)
+		return
+	}
+	alias.Confirm(config.ActionUpdate, display, func(cmd *cobra.Command, args []string) {
+		if err := changes.apply(cmd.Context(), cl, args[0]); err != nil {
+			messages.ExitErr(err)
+		}
+	})(cmd, args)
+}
+
+func exportSecurityGroup(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	sg, err := readSecurityGroup(cmd.Context(), cl, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "yaml", "", nil, false, false)
+	if err == nil {
+		rs := sgRuleSet{InboundRules: append([]sgRule{}, sg.InboundRules...), OutboundRules: append([]sgRule{}, sg.OutboundRules...)}
+		err = fmter.Format(cmd.Context(), os.Stdout, rs)
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var tagFindCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Lists all resources having a tag, grouped by resource type",
+	Run:   findTagged,
+}
+
+var tagApplyCmd = &cobra.Command{
+	Use:     "apply --tag key=value",
+	Short:   "Adds tags to all resources having a tag",
+	Example: "octl iaas tag apply --key env --value staging --tag owner=team-a",
+	Run:     applyTags,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "remove --tag key[=value]",
+	Aliases: []string{"rm"},
+	Short:   "Removes tags from all resources having a tag",
+	Example: "octl iaas tag remove --key env --value staging --tag owner",
+	Run:     removeTags,
+}
+
+var taggedColumns = config.Columns{
+	{Title: "Type", Content: ".ResourceType"},
+	{Title: "Count", Content: ".ResourceIds | length"},
+	{Title: "Resources", Content: `.ResourceIds | join(" ")`},
+}
+
+// tagBatchSize is the maximum number of resources tagged or untagged by a single call.
+const tagBatchSize = 100
+
+func addTagSelectorFlags(fs *pflag.FlagSet) {
+	fs.StringSlice("key", nil, "keys of the tags to select resources with")
+	fs.StringSlice("value", nil, "values of the tags to select resources with")
+	fs.StringSlice("resource-type", nil, "types of the resources to select (e.g. vm, volume, security-group)")
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{tagFindCmd, tagApplyCmd, tagRemoveCmd} {
+		addTagSelectorFlags(cmd.Flags())
+	}
+	tagApplyCmd.Flags().StringSlice("tag", nil, "tags to add, in the key=value format")
+	tagRemoveCmd.Flags().StringSlice("tag", nil, "tags to remove, in the key or key=value format - without a value, the tag is removed whatever its value")
+	_ = tagApplyCmd.MarkFlagRequired("tag")
+	_ = tagRemoveCmd.MarkFlagRequired("tag")
+}
+
+// tag is a tag of a resource, as returned by ReadTags.
+type tag struct {
+	key, value, resourceID, resourceType string
+}
+
+type taggedResources struct {
+	ResourceType string   `json:"ResourceType"`
+	ResourceIds  []string `json:"ResourceIds"`
+}
+
+// tagSelector returns the ReadTags filters set by the selector flags.
+func tagSelector(cmd *cobra.Command) map[string]any {
+	filters := map[string]any{}
+	for flag, filter := range map[string]string{"key": "Keys", "value": "Values", "resource-type": "ResourceTypes"} {
+		if values, _ := cmd.Flags().GetStringSlice(flag); len(values) > 0 {
+			filters[filter] = values
+		}
+	}
+	return filters
+}
+
+// readTags reads all tags matching filters.
+func readTags(ctx context.Context, cl *osc.Client, filters map[string]any) ([]tag, error) {
+	var tags []tag
+	req := map[string]any{"Filters": filters}
+	for {
+		resp, err := runner.CallJSON(ctx, cl, "ReadTags", req)
+		if err != nil {
+			return nil, fmt.Errorf("list tags: %w", err)
+		}
+		list, _ := resp["Tags"].([]any)
+		for _, t := range list {
+			t, _ := t.(map[string]any)
+			key, _ := t["Key"].(string)
+			value, _ := t["Value"].(string)
+			id, _ := t["ResourceId"].(string)
+			typ, _ := t["ResourceType"].(string)
+			tags = append(tags, tag{key: key, value: value, resourceID: id, resourceType: typ})
+		}
+		token, _ := resp["NextPageToken"].(string)
+		if token == "" {
+			return tags, nil
+		}
+		req["NextPageToken"] = token
+	}
+}
+
+// findResources returns all resources selected by the selector flags, grouped by resource type.
+func findResources(cmd *cobra.Command) (*osc.Client, []taggedResources) {
+	p := loadProfile(cmd)
+	cl, err := osc.NewClient(p, sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cancel := spinner.Run(cmd.Context(), "Finding resources...")
+	tags, err := readTags(cmd.Context(), cl, tagSelector(cmd))
+	cancel()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	groups := lo.GroupBy(tags, func(t tag) string { return t.resourceType })
+	types := lo.Keys(groups)
+	slices.Sort(types)
+	return cl, lo.Map(types, func(typ string, _ int) taggedResources {
+		ids := lo.Uniq(lo.Map(groups[typ], func(t tag, _ int) string { return t.resourceID }))
+		slices.Sort(ids)
+		return taggedResources{ResourceType: typ, ResourceIds: ids}
+	})
+}
+
+// displayTagged returns a run function displaying resources grouped by resource type.
+func displayTagged(found []taggedResources) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", taggedColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(found))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+}
+
+func findTagged(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	_, found := findResources(cmd)
+	displayTagged(found)(cmd, args)
+}
+
+// selectedIDs returns the resources selected by the selector flags and their IDs, a selector being required.
+func selectedIDs(cmd *cobra.Command) (*osc.Client, []taggedResources, []string) {
+	if len(tagSelector(cmd)) == 0 {
+		messages.ExitErr(errors.New("a selector is required (--key, --value or --resource-type)"))
+	}
+	cl, found := findResources(cmd)
+	ids := lo.FlatMap(found, func(tr taggedResources, _ int) []string { return tr.ResourceIds })
+	if len(ids) == 0 {
+		messages.Exit(1, "no resource found")
+	}
+	return cl, found, ids
+}
+
+func applyTags(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	kvs, _ := cmd.Flags().GetStringSlice("tag")
+	tags := make([]osc.ResourceTag, 0, len(kvs))
+	for _, kv := range kvs {
+		key, value, found := strings.Cut(kv, "=")
+		if !found || key == "" {
+			messages.ExitErr(fmt.Errorf("invalid tag %q, expecting key=value", kv))
+		}
+		tags = append(tags, osc.ResourceTag{Key: key, Value: value})
+	}
+	// the tagged resources are the ones displayed
+	cl, found, ids := selectedIDs(cmd)
+	alias.Confirm(config.ActionTag, displayTagged(found), func(cmd *cobra.Command, args []string) {
+		for _, batch := range lo.Chunk(ids, tagBatchSize) {
+			_, err := cl.CreateTags(cmd.Context(), osc.CreateTagsRequest{ResourceIds: batch, Tags: tags})
+			if err != nil {
+				messages.ExitErr(err)
+			}
+		}
+		messages.Success("%d resource(s) tagged.", len(ids))
+	})(cmd, args)
+}
+
+func removeTags(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	kvs, _ := cmd.Flags().GetStringSlice("tag")
+	// wanted stores the values to remove by key, a key without value removing all values
+	wanted := map[string][]string{}
+	anyValue := map[string]bool{}
+	for _, kv := range kvs {
+		key, value, found := strings.Cut(kv, "=")
+		if key == "" {
+			messages.ExitErr(fmt.Errorf("invalid tag %q, expecting key or key=value", kv))
+		}
+		wanted[key] = append(wanted[key], value)
+		anyValue[key] = anyValue[key] || !found
+	}
+	// the untagged resources are the ones displayed
+	cl, found, ids := selectedIDs(cmd)
+	alias.Confirm(config.ActionUntag, displayTagged(found), func(cmd *cobra.Command, args []string) {
+		// tags are deleted using their current value
+		var current []tag
+		for _, batch := range lo.Chunk(ids, tagBatchSize) {
+			tags, err := readTags(cmd.Context(), cl, map[string]any{"ResourceIds": batch, "Keys": lo.Keys(wanted)})
+			if err != nil {
+				messages.ExitErr(err)
+			}
+			current = append(current, tags...)
+		}
+		current = lo.Filter(current, func(t tag, _ int) bool {
+			return anyValue[t.key] || slices.Contains(wanted[t.key], t.value)
+		})
+		byTag := lo.GroupBy(current, func(t tag) osc.ResourceTag { return osc.ResourceTag{Key: t.key, Value: t.value} })
+		for rt, tagged := range byTag {
+			resIDs := lo.Map(tagged, func(t tag, _ int) string { return t.resourceID })
+			for _, batch := range lo.Chunk(resIDs, tagBatchSize) {
+				_, err := cl.DeleteTags(cmd.Context(), osc.DeleteTagsRequest{ResourceIds: batch, Tags: []osc.ResourceTag{rt}})
+				if err != nil {
+					messages.ExitErr(err)
+				}
+			}
+		}
+		messages.Success("%d tag(s) removed.", len(current))
+	})(cmd, args)
+}
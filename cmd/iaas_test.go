@@ -12,13 +12,19 @@ import (
 	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/outscale/octl/cmd"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/graph"
 	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -369,3 +375,421 @@ func TestReadCatalog(t *testing.T) {
 	runJSON(t, []string{"iaas", "catalog", "ls", "-o", "json"}, nil, &resp)
 	assert.NotEmpty(t, *resp.Entries)
 }
+
+func TestMockIAAS(t *testing.T) {
+	srv, args := mockArgs(t)
+
+	var net osc.Net
+	runJSON(t, args("iaas", "net", "create", "--ip-range", "10.0.0.0/16", "-o", "json"), nil, &net)
+	require.NotEmpty(t, net.NetId)
+	var subnet osc.Subnet
+	runJSON(t, args("iaas", "subnet", "create", "--net-id", net.NetId, "--ip-range", "10.0.1.0/24", "-o", "json"), nil, &subnet)
+	require.NotEmpty(t, subnet.SubnetId)
+	_ = run(t, args("iaas", "api", "CreateVms", "--ImageId", "ami-foo", "--SubnetId", subnet.SubnetId, "--MaxVmsCount", "3"), nil)
+
+	t.Run("Listings follow pages", func(t *testing.T) {
+		srv.SetPageSize(1)
+		defer srv.SetPageSize(0)
+		var vms []osc.Vm
+		runJSON(t, args("iaas", "vm", "list", "-o", "json"), nil, &vms)
+		assert.Len(t, vms, 3)
+		runJSON(t, args("iaas", "vm", "list", "-o", "json", "--max-pages", "2"), nil, &vms)
+		assert.Len(t, vms, 2)
+	})
+	t.Run("waitfor waits for VMs to be running", func(t *testing.T) {
+		var vms []osc.Vm
+		runJSON(t, args("iaas", "vm", "list", "-o", "json", "--waitfor", `all(.State=="running")`, "--waitfor-interval", "1s"), nil, &vms)
+		assert.Len(t, vms, 3)
+	})
+	t.Run("Dependencies of a VM are displayed", func(t *testing.T) {
+		var vms []osc.Vm
+		runJSON(t, args("iaas", "vm", "list", "-o", "json"), nil, &vms)
+		require.NotEmpty(t, vms)
+		deps := run(t, args("iaas", "vm", "dependencies", vms[0].VmId), nil)
+		assert.Contains(t, string(deps), "vm/"+vms[0].VmId)
+	})
+	t.Run("Dependencies of a net are exported", func(t *testing.T) {
+		var tree graph.TreeNode
+		runJSON(t, args("iaas", "net", "dependencies", net.NetId, "-o", "json"), nil, &tree)
+		assert.Equal(t, net.NetId, tree.ID)
+		assert.NotEmpty(t, tree.Children)
+		dot := run(t, args("iaas", "net", "dependencies", net.NetId, "-o", "dot"), nil)
+		assert.Contains(t, string(dot), "digraph {")
+		mermaid := run(t, args("iaas", "net", "dependencies", net.NetId, "-o", "mermaid"), nil)
+		assert.Contains(t, string(mermaid), "flowchart LR")
+	})
+	t.Run("An inventory of the account is written", func(t *testing.T) {
+		var inv map[string]json.RawMessage
+		runJSON(t, args("iaas", "inventory", "-o", "json"), nil, &inv)
+		var nets []osc.Net
+		require.NoError(t, json.Unmarshal(inv["net"], &nets))
+		require.Len(t, nets, 1)
+		assert.Equal(t, net.NetId, nets[0].NetId)
+
+		dir := filepath.Join(t.TempDir(), "inventory")
+		_ = run(t, args("iaas", "inventory", "-o", "yaml", "--dir", dir), nil)
+		assert.FileExists(t, filepath.Join(dir, "vm.yaml"))
+	})
+	t.Run("A reviewed deletion plan can be executed", func(t *testing.T) {
+		var vms []osc.Vm
+		runJSON(t, args("iaas", "vm", "list", "-o", "json"), nil, &vms)
+		require.NotEmpty(t, vms)
+		plan := run(t, args("iaas", "vm", "teardown", vms[0].VmId, "--dry-run", "-o", "json"), nil)
+		assert.Contains(t, string(plan), `"DeleteVms"`)
+		// the security group of the VM is shared with the other VMs of the net
+		assert.NotContains(t, string(plan), `"DeleteSecurityGroup"`)
+		path := file(t, "plan.json", string(plan))
+		_ = run(t, args("iaas", "vm", "teardown", "--from-plan", path, "-y"), nil)
+	})
+	t.Run("A net can be torn down", func(t *testing.T) {
+		_ = run(t, args("iaas", "net", "teardown", net.NetId, "--teardown-vms", "-y"), nil)
+		var nets []osc.Net
+		runJSON(t, args("iaas", "net", "list", "-o", "json"), nil, &nets)
+		assert.Empty(t, nets)
+	})
+}
+
+func TestMockJSONLines(t *testing.T) {
+	_, args := mockArgs(t)
+
+	var net osc.Net
+	runJSON(t, args("iaas", "net", "create", "--ip-range", "10.0.0.0/16", "-o", "json"), nil, &net)
+	require.NotEmpty(t, net.NetId)
+	// the failure of the first line does not prevent the second one from being processed
+	input := "{\"id\":\"vpc-00000000\"}\n{\"id\":\"" + net.NetId + "\"}\n"
+	runWithError(t, args("iaas", "api", "DeleteNet", "--NetId", "{{.id}}"), []byte(input))
+	var nets []osc.Net
+	runJSON(t, args("iaas", "net", "list", "-o", "json"), nil, &nets)
+	assert.Empty(t, nets)
+}
+
+func TestMockApply(t *testing.T) {
+	_, args := mockArgs(t)
+
+	path := file(t, "stack.yaml", `
+resources:
+  - kind: subnet
+    name: public
+    spec:
+      NetId: ${net.main.NetId}
+      IpRange: 10.0.1.0/24
+  - kind: securityGroup
+    name: web
+    spec:
+      SecurityGroupName: web
+      Description: web servers
+      NetId: ${net.main.NetId}
+  - kind: net
+    name: main
+    spec:
+      IpRange: 10.0.0.0/16
+    tags:
+      Name: main
+`)
+	summary := run(t, args("iaas", "apply", "-f", path), nil)
+	assert.Contains(t, string(summary), "(main)")
+
+	var nets []osc.Net
+	runJSON(t, args("iaas", "net", "list", "-o", "json"), nil, &nets)
+	require.Len(t, nets, 1)
+	require.Len(t, nets[0].Tags, 1)
+	assert.Equal(t, "main", nets[0].Tags[0].Value)
+	var subnets []osc.Subnet
+	runJSON(t, args("iaas", "subnet", "list", "-o", "json"), nil, &subnets)
+	require.Len(t, subnets, 1)
+	assert.Equal(t, nets[0].NetId, subnets[0].NetId)
+}
+
+func TestMockSecurityGroupAudit(t *testing.T) {
+	srv, args := mockArgs(t)
+	res, err := srv.Call("CreateSecurityGroup", map[string]any{"SecurityGroupName": "admin", "Description": "admin"})
+	require.NoError(t, err)
+	sgID := res["SecurityGroup"].(map[string]any)["SecurityGroupId"].(string)
+	_, err = srv.Call("CreateSecurityGroupRule", map[string]any{
+		"SecurityGroupId": sgID, "Flow": "Inbound", "IpProtocol": "tcp", "FromPortRange": 22, "ToPortRange": 22, "IpRange": "0.0.0.0/0",
+	})
+	require.NoError(t, err)
+	// the default group of a net allows all traffic from its members
+	res, err = srv.Call("CreateSecurityGroup", map[string]any{"SecurityGroupName": "default", "Description": "default"})
+	require.NoError(t, err)
+	defaultID := res["SecurityGroup"].(map[string]any)["SecurityGroupId"].(string)
+	_, err = srv.Call("CreateSecurityGroupRule", map[string]any{
+		"SecurityGroupId": defaultID, "Flow": "Inbound", "Rules": []any{map[string]any{
+			"IpProtocol": "-1", "FromPortRange": -1, "ToPortRange": -1,
+			"SecurityGroupsMembers": []any{map[string]any{"SecurityGroupId": defaultID}},
+		}},
+	})
+	require.NoError(t, err)
+
+	var findings []map[string]any
+	runJSON(t, args("iaas", "securitygroup", "audit", "-o", "json"), nil, &findings)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "high", findings[0]["Severity"])
+	assert.Equal(t, "SSH port 22 open to the world", findings[0]["Finding"])
+	assert.Equal(t, "low", findings[1]["Severity"])
+	runWithError(t, args("iaas", "securitygroup", "audit", "-o", "json", "--fail-on", "high"), nil)
+}
+
+func TestMockSecurityGroupSync(t *testing.T) {
+	srv, args := mockArgs(t)
+	res, err := srv.Call("CreateSecurityGroup", map[string]any{"SecurityGroupName": "web", "Description": "web"})
+	require.NoError(t, err)
+	sgID := res["SecurityGroup"].(map[string]any)["SecurityGroupId"].(string)
+	_, err = srv.Call("CreateSecurityGroupRule", map[string]any{
+		"SecurityGroupId": sgID, "Flow": "Inbound", "IpProtocol": "tcp", "FromPortRange": 22, "ToPortRange": 22, "IpRange": "0.0.0.0/0",
+	})
+	require.NoError(t, err)
+
+	path := file(t, "rules.yaml", `
+InboundRules:
+  - IpProtocol: tcp
+    FromPortRange: 443
+    ToPortRange: 443
+    IpRanges:
+      - 0.0.0.0/0
+`)
+	plan := run(t, args("iaas", "securitygroup", "sync", sgID, "-f", path, "--plan"), nil)
+	assert.Contains(t, string(plan), "tcp 22 from 0.0.0.0/0")
+	assert.Contains(t, string(plan), "tcp 443 from 0.0.0.0/0")
+	_ = run(t, args("iaas", "securitygroup", "sync", sgID, "-f", path, "-y"), nil)
+
+	var rules map[string][]map[string]any
+	runJSON(t, args("iaas", "securitygroup", "export", sgID, "-o", "json"), nil, &rules)
+	require.Len(t, rules["InboundRules"], 1)
+	assert.EqualValues(t, 443, rules["InboundRules"][0]["FromPortRange"])
+}
+
+func TestMockVMSSH(t *testing.T) {
+	srv, args := mockArgs(t)
+	res, err := srv.Call("CreateNet", map[string]any{"IpRange": "10.0.0.0/16"})
+	require.NoError(t, err)
+	netID := res["Net"].(map[string]any)["NetId"].(string)
+	res, err = srv.Call("CreateSubnet", map[string]any{"NetId": netID, "IpRange": "10.0.1.0/24"})
+	require.NoError(t, err)
+	subnetID := res["Subnet"].(map[string]any)["SubnetId"].(string)
+	res, err = srv.Call("CreateVms", map[string]any{"ImageId": "ami-foo", "SubnetId": subnetID, "KeypairName": "demo"})
+	require.NoError(t, err)
+	vm := res["Vms"].([]any)[0].(map[string]any)
+	_, err = srv.Call("CreateTags", map[string]any{"ResourceIds": []any{vm["VmId"]}, "Tags": []any{map[string]any{"Key": "Name", "Value": "web"}}})
+	require.NoError(t, err)
+	_ = run(t, args("iaas", "vm", "list", "--waitfor", `all(.State=="running")`, "--waitfor-interval", "1s"), nil)
+
+	key := file(t, "demo.pem", "key")
+	line := run(t, args("iaas", "vm", "ssh", "web", "--private", "--print", "--key-dir", filepath.Dir(key), "--", "uptime"), nil)
+	assert.Equal(t, fmt.Sprintf("ssh -i %s -- outscale@%s uptime\n", key, vm["PrivateIp"]), string(line))
+	runWithError(t, args("iaas", "vm", "ssh", "web", "--print"), nil)
+	runWithError(t, args("iaas", "vm", "ssh", "unknown", "--print"), nil)
+}
+
+func TestMockVMConsole(t *testing.T) {
+	srv, args := mockArgs(t)
+	res, err := srv.Call("CreateVms", map[string]any{"ImageId": "ami-foo"})
+	require.NoError(t, err)
+	vmID := res["Vms"].([]any)[0].(map[string]any)["VmId"].(string)
+
+	out := string(run(t, args("iaas", "vm", "console", vmID, "--until", "Cloud-init .* finished", "--interval", "100ms"), nil))
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, 1, strings.Count(out, fmt.Sprintf("boot step %d\n", i)), out)
+	}
+	assert.True(t, strings.HasSuffix(out, "Cloud-init v. 24.1 finished\n"), out)
+	runWithError(t, args("iaas", "vm", "console", vmID, "--until", "("), nil)
+}
+
+func TestMockOrphans(t *testing.T) {
+	srv, args := mockArgs(t)
+	res, err := srv.Call("CreateVolume", map[string]any{"SubregionName": "eu-west-2a", "Size": 10})
+	require.NoError(t, err)
+	volID := res["Volume"].(map[string]any)["VolumeId"].(string)
+
+	var found []map[string]any
+	runJSON(t, args("iaas", "orphans", "--category", "volume", "-o", "json"), nil, &found)
+	assert.Empty(t, found)
+	runJSON(t, args("iaas", "orphans", "--category", "volume", "--rule", "volume=+1d", "-o", "json"), nil, &found)
+	require.Len(t, found, 1)
+	assert.Equal(t, volID, found[0]["Id"])
+	runWithError(t, args("iaas", "orphans", "--rule", "publicip=-1d"), nil)
+
+	_ = run(t, args("iaas", "orphans", "--category", "volume", "--rule", "volume=", "--delete", "-y"), nil)
+	var volumes []osc.Volume
+	runJSON(t, args("iaas", "volume", "list", "-o", "json"), nil, &volumes)
+	assert.Empty(t, volumes)
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv, args := mockArgs(t)
+	_, err := srv.Call("CreateNet", map[string]any{"IpRange": "10.0.0.0/16"})
+	require.NoError(t, err)
+
+	cassette := filepath.Join(t.TempDir(), "cassette.yaml")
+	recorded := run(t, args("iaas", "net", "list", "-o", "table", "--record", cassette), nil)
+	srv.Close()
+	replayed := run(t, args("iaas", "net", "list", "-o", "table", "--replay", cassette), nil)
+	assert.Equal(t, string(recorded), string(replayed))
+	runWithError(t, args("iaas", "vm", "list", "--replay", cassette), nil)
+}
+
+func TestMockTags(t *testing.T) {
+	_, args := mockArgs(t)
+
+	var net osc.Net
+	runJSON(t, args("iaas", "net", "create", "--ip-range", "10.0.0.0/16", "-o", "json"), nil, &net)
+	var subnet osc.Subnet
+	runJSON(t, args("iaas", "subnet", "create", "--net-id", net.NetId, "--ip-range", "10.0.1.0/24", "-o", "json"), nil, &subnet)
+	before := file(t, "nets.json", string(run(t, args("iaas", "net", "list", "-o", "json"), nil)))
+	_ = run(t, args("iaas", "tag", "create", "--resource-id", net.NetId+","+subnet.SubnetId, "--key", "env", "--value", "staging"), nil)
+
+	t.Run("diff reports tag changes against the live state", func(t *testing.T) {
+		var drifts []map[string]any
+		runJSON(t, args("iaas", "diff", before, "-o", "json"), nil, &drifts)
+		require.Len(t, drifts, 1)
+		assert.Equal(t, map[string]any{
+			"Entity": "net", "Id": net.NetId, "Change": "added", "Field": "Tags.env", "Next": "staging",
+		}, drifts[0])
+	})
+	t.Run("find groups resources by type", func(t *testing.T) {
+		var found []map[string]any
+		runJSON(t, args("iaas", "tag", "find", "--key", "env", "--value", "staging", "-o", "json"), nil, &found)
+		assert.Len(t, found, 2)
+	})
+	t.Run("apply fails before confirming without a selector", func(t *testing.T) {
+		out, err := try(t.Context(), args("iaas", "tag", "apply", "--tag", "owner=team-a"), []byte("y\n"))
+		require.Error(t, err)
+		assert.Empty(t, out)
+	})
+	t.Run("apply tags all selected resources", func(t *testing.T) {
+		_ = run(t, args("iaas", "tag", "apply", "--key", "env", "--tag", "owner=team-a", "-y"), nil)
+		var found []map[string]any
+		runJSON(t, args("iaas", "tag", "find", "--key", "owner", "-o", "json"), nil, &found)
+		assert.Len(t, found, 2)
+	})
+	t.Run("remove untags all selected resources", func(t *testing.T) {
+		_ = run(t, args("iaas", "tag", "remove", "--key", "env", "--tag", "owner", "-y"), nil)
+		var found []map[string]any
+		runJSON(t, args("iaas", "tag", "find", "--key", "owner", "-o", "json"), nil, &found)
+		assert.Empty(t, found)
+	})
+}
+
+func TestCompareRules(t *testing.T) {
+	ssh := `{"IpProtocol":"tcp","FromPortRange":22,"ToPortRange":22,"IpRanges":["10.0.0.0/8"]}`
+	tests := []struct {
+		name            string
+		current, wanted string
+		added, removed  []string
+	}{
+		{
+			name:    "identical rules",
+			current: `{"InboundRules":[` + ssh + `]}`,
+			wanted:  `{"InboundRules":[` + ssh + `]}`,
+		},
+		{
+			name:    "missing rule",
+			current: `{}`,
+			wanted:  `{"InboundRules":[` + ssh + `]}`,
+			added:   []string{"Inbound tcp 22 from 10.0.0.0/8"},
+		},
+		{
+			name:    "extra rule",
+			current: `{"InboundRules":[` + ssh + `],"OutboundRules":[{"IpProtocol":"-1","IpRanges":["0.0.0.0/0"]}]}`,
+			wanted:  `{"InboundRules":[` + ssh + `]}`,
+			removed: []string{"Outbound all all from 0.0.0.0/0"},
+		},
+		{
+			name:    "rules are compared by source",
+			current: `{"InboundRules":[{"IpProtocol":"tcp","FromPortRange":22,"ToPortRange":22,"IpRanges":["10.0.0.0/8","192.168.0.0/16"]}]}`,
+			wanted:  `{"InboundRules":[{"IpProtocol":"TCP","FromPortRange":22,"ToPortRange":22,"IpRanges":["10.0.0.0/8","172.16.0.0/12"]}]}`,
+			added:   []string{"Inbound tcp 22 from 172.16.0.0/12"},
+			removed: []string{"Inbound tcp 22 from 192.168.0.0/16"},
+		},
+		{
+			name:    "the account of a member is ignored",
+			current: `{"InboundRules":[{"IpProtocol":"tcp","FromPortRange":443,"ToPortRange":443,"SecurityGroupsMembers":[{"AccountId":"123","SecurityGroupId":"sg-foo"}]}]}`,
+			wanted:  `{"InboundRules":[{"IpProtocol":"tcp","FromPortRange":443,"ToPortRange":443,"SecurityGroupsMembers":[{"SecurityGroupId":"sg-foo"}]}]}`,
+		},
+		{
+			name:    "the ports of all protocols are ignored",
+			current: `{"OutboundRules":[{"IpProtocol":"-1","FromPortRange":0,"ToPortRange":0,"IpRanges":["0.0.0.0/0"]}]}`,
+			wanted:  `{"OutboundRules":[{"IpProtocol":"-1","IpRanges":["0.0.0.0/0"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, err := cmd.CompareRules(tt.current, tt.wanted)
+			require.NoError(t, err)
+			assert.Equal(t, tt.added, added)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
+
+func TestConsoleStart(t *testing.T) {
+	long := strings.Repeat("boot message\n", 20)
+	tests := []struct {
+		name      string
+		seen, out string
+		start     int
+	}{
+		{name: "nothing displayed", seen: "", out: "login:", start: 0},
+		{name: "output grows", seen: "line 1\n", out: "line 1\nline 2\n", start: 7},
+		{name: "output unchanged", seen: "line 1\n", out: "line 1\n", start: 7},
+		{name: "beginning of the buffer dropped", seen: "dropped\n" + long, out: long + "login:", start: len(long)},
+		{name: "output reset", seen: long, out: "BIOS\n", start: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.start, cmd.ConsoleStart(tt.seen, tt.out))
+		})
+	}
+}
+
+type inventoryRequest struct{ Filters *struct{} }
+
+type inventoryParamsRequest struct{ PolicyOrn string }
+
+type inventoryResponse struct {
+	Catalog   *struct{}
+	Catalogs  *[]struct{}
+	Policy    *struct{}
+	Policies  *[]struct{}
+	VmsStates *[]struct{}
+}
+
+type inventoryClient struct{}
+
+func (inventoryClient) ReadCatalog(context.Context, inventoryRequest) (*inventoryResponse, error) {
+	return nil, nil
+}
+
+func (inventoryClient) ReadCatalogs(context.Context, inventoryRequest) (*inventoryResponse, error) {
+	return nil, nil
+}
+
+func (inventoryClient) ReadPolicies(context.Context, inventoryRequest) (*inventoryResponse, error) {
+	return nil, nil
+}
+
+func (inventoryClient) ReadPolicy(context.Context, inventoryParamsRequest) (*inventoryResponse, error) {
+	return nil, nil
+}
+
+func (inventoryClient) ReadVmsState(context.Context, inventoryRequest) (*inventoryResponse, error) {
+	return nil, nil
+}
+
+func TestInventoryCalls(t *testing.T) {
+	cfg := config.Config{Calls: map[string]config.Call{
+		"ReadCatalog":  {Content: "Catalog", Entity: "catalog"},
+		"ReadCatalogs": {Content: "Catalogs", Entity: "catalog"},
+		"ReadPolicies": {Content: "Policies", Entity: "policy"},
+		"ReadPolicy":   {Content: "Policy", Entity: "policy"},
+		"ReadVmsState": {Content: "VmsStates", Entity: "vmsstate"},
+		"ReadNets":     {Content: "Nets", Entity: "net"},
+	}}
+	assert.Equal(t, map[string]string{
+		// listings are preferred, whatever their order
+		"catalog":  "ReadCatalogs",
+		"policy":   "ReadPolicies",
+		"vmsstate": "ReadVmsState",
+		// ReadNets is not a method of the client
+	}, cmd.InventoryCalls(cfg, reflect.TypeFor[inventoryClient]()))
+}
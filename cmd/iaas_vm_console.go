@@ -0,0 +1,208 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/spf13/cobra"
+)
+
+var vmConsoleCmd = &cobra.Command{
+	Use:   "console vm_id|vm_name",
+	Short: "Displays the console output of a VM",
+	Long: `Displays the console output of a VM, the VM being set by its ID or its Name tag.
+
+With --follow, the console output is polled and only the new lines are displayed, until the VM reaches the state set by
+--until-state, or a line matches the regular expression set by --until. --until and --until-state imply --follow.
+
+The console output of a VM is only refreshed from time to time by the API, new lines may take a few minutes to appear.`,
+	Example: `octl iaas vm console web-1
+octl iaas vm console i-12345678 --follow
+octl iaas vm console web-1 --until 'Cloud-init .* finished'
+octl iaas vm console web-1 --until-state stopped --timeout 10m`,
+	Args: cobra.ExactArgs(1),
+	Run:  vmConsole,
+}
+
+func init() {
+	vmConsoleCmd.Flags().BoolP("follow", "f", false, "poll the console output and display new lines")
+	vmConsoleCmd.Flags().Duration("interval", 5*time.Second, "interval between two polls")
+	vmConsoleCmd.Flags().String("until", "", "stop when a line matches this regular expression")
+	vmConsoleCmd.Flags().String("until-state", "", "stop when the VM reaches this state, e.g. running or stopped")
+	vmConsoleCmd.Flags().Duration("timeout", 0, "maximum duration of a follow, 0 for no limit")
+}
+
+// consoleOverlap is the minimum length of the displayed output found at the beginning of a new console output,
+// when the console buffer has rotated.
+const consoleOverlap = 64
+
+// consoleFollower tracks the part of the console output already displayed.
+type consoleFollower struct {
+	// seen is the displayed part of the last console output.
+	seen string
+}
+
+// next returns the complete lines of a console output which have not been displayed yet.
+func (f *consoleFollower) next(out string) string {
+	start := f.start(out)
+	end := strings.LastIndexByte(out[start:], '\n')
+	if end < 0 {
+		f.seen = out[:start]
+		return ""
+	}
+	end += start + 1
+	f.seen = out[:end]
+	return out[start:end]
+}
+
+// flush returns the part of a console output which has not been displayed yet, including an incomplete last line.
+func (f *consoleFollower) flush(out string) string {
+	start := f.start(out)
+	f.seen = out
+	return out[start:]
+}
+
+// start returns the position of the first byte of a console output not displayed yet.
+func (f *consoleFollower) start(out string) int {
+	if strings.HasPrefix(out, f.seen) {
+		return len(f.seen)
+	}
+	// the beginning of the buffer has been dropped, the end of the displayed output starts the new output
+	minOverlap := min(consoleOverlap, len(f.seen)/2)
+	for i := 1; i <= len(f.seen)-max(minOverlap, 1); i++ {
+		if strings.HasPrefix(out, f.seen[i:]) {
+			return len(f.seen) - i
+		}
+	}
+	// the VM has been rebooted, or too much has been written since the last poll
+	debug.Println("console output has been reset")
+	return 0
+}
+
+// readConsole returns the decoded console output of a VM.
+func readConsole(ctx context.Context, cl *osc.Client, id string) (string, error) {
+	resp, err := runner.CallJSON(ctx, cl, "ReadConsoleOutput", map[string]any{"VmId": id})
+	if err != nil {
+		return "", fmt.Errorf("read console output: %w", err)
+	}
+	// the output is missing until the VM has written something
+	encoded, _ := resp["ConsoleOutput"].(string)
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decode console output: %w", err)
+	}
+	return string(buf), nil
+}
+
+// firstMatch returns the text up to the end of the first line matching re, and whether a line has matched.
+func firstMatch(text string, re *regexp.Regexp) (string, bool) {
+	if re == nil {
+		return text, false
+	}
+	pos := 0
+	for line := range strings.Lines(text) {
+		pos += len(line)
+		if re.MatchString(strings.TrimRight(line, "\r\n")) {
+			return text[:pos], true
+		}
+	}
+	return text, false
+}
+
+// followConsole displays the new lines of the console output of a VM, until a line matches until or the VM reaches untilState.
+func followConsole(ctx context.Context, cl *osc.Client, vm *vmInfo, until *regexp.Regexp, untilState string, interval time.Duration) error {
+	var f consoleFollower
+	for {
+		out, err := readConsole(ctx, cl, vm.VmId)
+		if err != nil {
+			return err
+		}
+		text, matched := firstMatch(f.next(out), until)
+		fmt.Print(text)
+		if matched {
+			return nil
+		}
+		if untilState != "" {
+			// findVM ignores VMs being deleted
+			deleted := untilState == "terminated" || untilState == "shutting-down"
+			cur, err := findVM(ctx, cl, vm.VmId)
+			switch {
+			case err != nil && !deleted:
+				return err
+			case err != nil || cur.State == untilState:
+				// the output written before the state change may not be complete yet
+				if out, err := readConsole(ctx, cl, vm.VmId); err == nil {
+					fmt.Print(f.flush(out))
+				}
+				messages.Info("%s is %s.", vm.VmId, untilState)
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func vmConsole(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	follow, _ := cmd.Flags().GetBool("follow")
+	var until *regexp.Regexp
+	if expr, _ := cmd.Flags().GetString("until"); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			messages.ExitErr(fmt.Errorf("invalid --until expression: %w", err))
+		}
+		until, follow = re, true
+	}
+	untilState, _ := cmd.Flags().GetString("until-state")
+	follow = follow || untilState != ""
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	vm, err := findVM(cmd.Context(), cl, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if !follow {
+		out, err := readConsole(cmd.Context(), cl, vm.VmId)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+		fmt.Print(out)
+		return
+	}
+	ctx := cmd.Context()
+	if tmout, _ := cmd.Flags().GetDuration("timeout"); tmout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tmout)
+		defer cancel()
+	}
+	interval, _ := cmd.Flags().GetDuration("interval")
+	err = followConsole(ctx, cl, vm, until, untilState, interval)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && (until != nil || untilState != ""):
+		messages.Exit(1, "timeout waiting for the console of %s", vm.VmId)
+	case errors.Is(err, context.DeadlineExceeded):
+		// following without condition, the timeout is the expected end
+	case err != nil:
+		messages.ExitErr(err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/outscale/goutils/sdk/tags"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func vmGraph(cmd *cobra.Command, vmID string) (*graph.Graph, error) {
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	return listVMResources(cmd.Context(), cl, vmID)
+}
+
+// listVMResources builds the graph of a VM and of the resources it uses.
+// The keypair of the VM, and its security groups and public IPs used by other resources, are never deleted.
+func listVMResources(ctx context.Context, cl *osc.Client, vmID string) (*graph.Graph, error) {
+	g := graph.New()
+	users, err := readResourceUsers(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := cl.ReadVms(ctx, osc.ReadVmsRequest{Filters: &osc.FiltersVm{VmIds: &[]string{vmID}}})
+	if err != nil {
+		return nil, fmt.Errorf("list vms: %w", err)
+	}
+	if len(*vms.Vms) == 0 {
+		return nil, errors.New("no vm found")
+	}
+	vm := (*vms.Vms)[0]
+	root := g.Add(nil, &graph.Node{
+		Type:  VM,
+		ID:    vmID,
+		Name:  tags.Must(tags.GetName(vm.Tags)),
+		Calls: deleteVm(vmID),
+	})
+
+	vols, err := cl.ReadVolumes(ctx, osc.ReadVolumesRequest{Filters: &osc.FiltersVolume{LinkVolumeVmIds: &[]string{vmID}}})
+	if err != nil {
+		return nil, fmt.Errorf("list volumes: %w", err)
+	}
+	for _, vol := range *vols.Volumes {
+		g.Use(root, &graph.Node{
+			Type:  Volume,
+			ID:    vol.VolumeId,
+			Name:  tags.Must(tags.GetName(vol.Tags)),
+			Calls: deleteVolume(vol.VolumeId),
+		})
+	}
+
+	nics, err := cl.ReadNics(ctx, osc.ReadNicsRequest{Filters: &osc.FiltersNic{LinkNicVmIds: &[]string{vmID}}})
+	if err != nil {
+		return nil, fmt.Errorf("list nics: %w", err)
+	}
+	for _, nic := range *nics.Nics {
+		nicr := g.Use(root, &graph.Node{
+			Type:  NIC,
+			ID:    nic.NicId,
+			Calls: deleteNIC(nic.NicId, lo.FromPtr(nic.LinkNic).LinkNicId),
+		})
+		if nic.LinkPublicIp == nil {
+			continue
+		}
+		pips, err := cl.ReadPublicIps(ctx, osc.ReadPublicIpsRequest{
+			Filters: &osc.FiltersPublicIp{PublicIps: &[]string{nic.LinkPublicIp.PublicIp}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list public ip: %w", err)
+		}
+		for _, pip := range *pips.PublicIps {
+			g.Use(nicr, &graph.Node{
+				Type:  PublicIP,
+				ID:    pip.PublicIpId,
+				Name:  pip.PublicIp,
+				Calls: onlyUsedBy(users.publicIPs[pip.PublicIp], vmID, deletePublicIP(pip.PublicIpId)),
+			})
+		}
+	}
+	// public IPs of VMs outside of a net are not linked to a NIC
+	pips, err := cl.ReadPublicIps(ctx, osc.ReadPublicIpsRequest{Filters: &osc.FiltersPublicIp{VmIds: &[]string{vmID}}})
+	if err != nil {
+		return nil, fmt.Errorf("list public ip: %w", err)
+	}
+	for _, pip := range *pips.PublicIps {
+		g.Use(root, &graph.Node{
+			Type:  PublicIP,
+			ID:    pip.PublicIpId,
+			Name:  pip.PublicIp,
+			Calls: onlyUsedBy(users.publicIPs[pip.PublicIp], vmID, deletePublicIP(pip.PublicIpId)),
+		})
+	}
+
+	for _, sg := range vm.SecurityGroups {
+		g.Use(root, &graph.Node{
+			Type:  SecurityGroup,
+			ID:    sg.SecurityGroupId,
+			Name:  sg.SecurityGroupName,
+			Calls: onlyUsedBy(users.securityGroups[sg.SecurityGroupId], vmID, deleteSecurityGroup(sg.SecurityGroupId)),
+		})
+	}
+	if name := lo.FromPtr(vm.KeypairName); name != "" {
+		g.Use(root, &graph.Node{Type: Keypair, ID: name})
+	}
+	return g, nil
+}
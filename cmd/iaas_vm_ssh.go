@@ -0,0 +1,220 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var vmSSHCmd = &cobra.Command{
+	Use:   "ssh vm_id|vm_name [-- command]",
+	Short: "Connects to a VM with ssh",
+	Long: `Connects to a VM with ssh, the VM being set by its ID or its Name tag.
+
+The public IP of the VM is used, or its private IP with --private. The login user is guessed from the image of the VM,
+and can be set with --user. The private key is searched in the key directory, as <keypair name>, <keypair name>.pem or
+<keypair name>.rsa. If no key is found, the ssh agent and the ssh configuration are used.`,
+	Example: `octl iaas vm ssh web-1
+octl iaas vm ssh i-12345678 --private -- uptime
+octl iaas vm ssh web-1 --print`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  sshVM,
+}
+
+func init() {
+	vmSSHCmd.Flags().Bool("private", false, "connect to the private IP of the VM")
+	vmSSHCmd.Flags().String("user", "", "login user - guessed from the image of the VM by default")
+	vmSSHCmd.Flags().String("key-dir", "~/.ssh", "directory of the private keys, named after keypairs")
+	vmSSHCmd.Flags().Bool("print", false, "only print the ssh command line")
+}
+
+// loginUsers are the login users of the Linux distributions, matched against image names.
+// Images provided by OUTSCALE all use the outscale user.
+var loginUsers = []struct {
+	pattern *regexp.Regexp
+	user    string
+}{
+	{regexp.MustCompile(`(?i)ubuntu`), "ubuntu"},
+	{regexp.MustCompile(`(?i)debian`), "admin"},
+	{regexp.MustCompile(`(?i)centos`), "centos"},
+	{regexp.MustCompile(`(?i)rocky`), "rocky"},
+	{regexp.MustCompile(`(?i)alma`), "almalinux"},
+	{regexp.MustCompile(`(?i)fedora`), "fedora"},
+	{regexp.MustCompile(`(?i)rhel|red ?hat`), "ec2-user"},
+	{regexp.MustCompile(`(?i)windows`), "Administrator"},
+}
+
+const defaultLoginUser = "outscale"
+
+// vmInfo is a VM, as returned by the API.
+type vmInfo struct {
+	VmId        string
+	State       string
+	ImageId     string
+	KeypairName string
+	PublicIp    string
+	PrivateIp   string
+}
+
+// findVM finds a VM by ID or by Name tag.
+func findVM(ctx context.Context, cl *osc.Client, idOrName string) (*vmInfo, error) {
+	filters := map[string]any{"Tags": []string{"Name=" + idOrName}}
+	if strings.HasPrefix(idOrName, "i-") {
+		filters = map[string]any{"VmIds": []string{idOrName}}
+	}
+	resp, err := runner.CallJSON(ctx, cl, "ReadVms", map[string]any{"Filters": filters})
+	if err != nil {
+		return nil, fmt.Errorf("read vms: %w", err)
+	}
+	var vms []vmInfo
+	if err := fromJSON(resp["Vms"], &vms); err != nil {
+		return nil, err
+	}
+	vms = lo.Reject(vms, func(vm vmInfo, _ int) bool { return vm.State == "terminated" || vm.State == "shutting-down" })
+	switch len(vms) {
+	case 0:
+		return nil, fmt.Errorf("vm %q not found", idOrName)
+	case 1:
+		return &vms[0], nil
+	default:
+		ids := lo.Map(vms, func(vm vmInfo, _ int) string { return vm.VmId })
+		return nil, fmt.Errorf("%d vms are named %q (%s), use an ID", len(vms), idOrName, strings.Join(ids, ", "))
+	}
+}
+
+// loginUser guesses the login user of a VM from its image.
+func loginUser(ctx context.Context, cl *osc.Client, imageID string) string {
+	resp, err := runner.CallJSON(ctx, cl, "ReadImages", map[string]any{"Filters": map[string]any{"ImageIds": []string{imageID}}})
+	if err != nil {
+		debug.Println("unable to read image:", err)
+		return defaultLoginUser
+	}
+	var images []struct {
+		ImageName    string
+		AccountAlias string
+	}
+	if err := fromJSON(resp["Images"], &images); err != nil || len(images) == 0 || images[0].AccountAlias == "Outscale" {
+		return defaultLoginUser
+	}
+	for _, lu := range loginUsers {
+		if lu.pattern.MatchString(images[0].ImageName) {
+			return lu.user
+		}
+	}
+	return defaultLoginUser
+}
+
+// privateKey returns the path of the private key of a keypair, or an empty string if none is found.
+func privateKey(dir, keypair string) (string, error) {
+	if keypair == "" {
+		return "", nil
+	}
+	if rest, found := strings.CutPrefix(dir, "~"); found {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("key dir: %w", err)
+		}
+		dir = filepath.Join(home, rest)
+	}
+	for _, ext := range []string{"", ".pem", ".rsa"} {
+		path := filepath.Join(dir, keypair+ext)
+		if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+			return path, nil
+		}
+	}
+	debug.Println("no private key found for", keypair, "in", dir)
+	return "", nil
+}
+
+// sshArgs returns the ssh command line connecting to a VM.
+func sshArgs(cmd *cobra.Command, cl *osc.Client, vm *vmInfo, command []string) ([]string, error) {
+	if vm.State != "running" {
+		return nil, fmt.Errorf("vm %s is %s", vm.VmId, vm.State)
+	}
+	ip := vm.PublicIp
+	if private, _ := cmd.Flags().GetBool("private"); private {
+		ip = vm.PrivateIp
+	} else if ip == "" {
+		return nil, fmt.Errorf("vm %s has no public IP, use --private to connect to its private IP %s", vm.VmId, vm.PrivateIp)
+	}
+	if ip == "" {
+		return nil, fmt.Errorf("vm %s has no private IP", vm.VmId)
+	}
+	user, _ := cmd.Flags().GetString("user")
+	if user == "" {
+		user = loginUser(cmd.Context(), cl, vm.ImageId)
+	}
+	dir, _ := cmd.Flags().GetString("key-dir")
+	key, err := privateKey(dir, vm.KeypairName)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"ssh"}
+	if key != "" {
+		args = append(args, "-i", key)
+	}
+	// the remote command may start with a dash
+	args = append(args, "--", user+"@"+ip)
+	return append(args, command...), nil
+}
+
+// shellQuote quotes an argument for a POSIX shell, if needed.
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+	}) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func sshVM(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	cl, err := osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	vm, err := findVM(cmd.Context(), cl, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	sargs, err := sshArgs(cmd, cl, vm, args[1:])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		fmt.Println(strings.Join(lo.Map(sargs, func(a string, _ int) string { return shellQuote(a) }), " "))
+		return
+	}
+	path, err := exec.LookPath("ssh")
+	if err != nil {
+		messages.ExitErr(fmt.Errorf("ssh client not found: %w", err))
+	}
+	debug.Println("running", sargs)
+	c := exec.CommandContext(cmd.Context(), path, sargs[1:]...) //nolint:gosec
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	var exitErr *exec.ExitError
+	switch err := c.Run(); {
+	case errors.As(err, &exitErr):
+		messages.Quit(exitErr.ExitCode())
+	case err != nil:
+		messages.ExitErr(err)
+	}
+}
@@ -39,6 +39,12 @@ func init() {
 	b.Build(oksCmd, nil)
 
 	oksCmd.AddCommand(kubectlCmd)
+
+	cmd, _, err := oksCmd.Find([]string{"project"})
+	if err != nil {
+		panic(err)
+	}
+	cmd.AddCommand(dependencyCommands(dependencyEntity{service: "kube", name: "project", arg: "project_id", resolve: projectGraph}))
 }
 
 func kube(cmd *cobra.Command, args []string) {
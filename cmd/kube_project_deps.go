@@ -0,0 +1,71 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oks"
+	"github.com/spf13/cobra"
+)
+
+const (
+	Project graph.Type = "project"
+	Cluster graph.Type = "cluster"
+)
+
+func projectGraph(cmd *cobra.Command, id string) (*graph.Graph, error) {
+	cl, err := oks.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	name := ""
+	if _, err := uuid.Parse(id); err != nil {
+		name = id
+		id, err = projectNameToID(cmd.Context(), name, cl)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return listProjectResources(cmd.Context(), cl, id, name)
+}
+
+// listProjectResources builds the graph of an OKS project and of its clusters.
+func listProjectResources(ctx context.Context, cl *oks.Client, id, name string) (*graph.Graph, error) {
+	g := graph.New()
+	root := g.Add(nil, &graph.Node{
+		Type:  Project,
+		ID:    id,
+		Name:  name,
+		Calls: []graph.Call{{Call: "DeleteProject", Params: id}},
+	})
+	cs, err := cl.ListAllClusters(ctx, &oks.ListAllClustersParams{ProjectId: &id})
+	if err != nil {
+		return nil, fmt.Errorf("list clusters: %w", err)
+	}
+	for _, c := range cs.Clusters {
+		g.Add(root, &graph.Node{
+			Type:  Cluster,
+			ID:    c.Id,
+			Name:  c.Name,
+			Calls: []graph.Call{{Call: "DeleteCluster", Params: c.Id}},
+		})
+	}
+	return g, nil
+}
+
+// kubeGoneCalls are the reads of the deleted OKS resources, by type.
+var kubeGoneCalls = map[graph.Type]func(id string) goneCall{
+	Project: func(string) goneCall {
+		return goneCall{graph.Call{Call: "ListProjects", Params: oks.ListProjectsParams{}}, "Projects", "Id"}
+	},
+	Cluster: func(string) goneCall {
+		return goneCall{graph.Call{Call: "ListAllClusters", Params: oks.ListAllClustersParams{}}, "Clusters", "Id"}
+	},
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/outscale/octl/cmd/prerun"
 	"github.com/outscale/octl/pkg/markdown"
+	"github.com/outscale/octl/pkg/output/read"
 	"github.com/outscale/octl/pkg/version"
 	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
 	"github.com/outscale/osc-sdk-go/v3/pkg/profile"
@@ -78,6 +79,9 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().String("config", "", "Path of profile file (by default, ~/.osc/config.json)")
 	rootCmd.PersistentFlags().String("profile", "", fmt.Sprintf("Profile to use in profile file (by default, %q)", profile.DefaultProfile))
+	rootCmd.PersistentFlags().String("record", "", "record all HTTP exchanges in a cassette file - credentials and signatures are redacted")
+	rootCmd.PersistentFlags().String("replay", "", "serve HTTP responses from a cassette file written by --record, without network access")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
 
 	rootCmd.PersistentFlags().String("template", "", "JSON template file for query body")
 	rootCmd.PersistentFlags().String("template-root", "", "the root attribute for the template")
@@ -92,9 +96,12 @@ func init() {
 	rootCmd.PersistentFlags().Duration("waitfor-timeout", 10*time.Minute, `maximum duration of a wait`)
 
 	rootCmd.PersistentFlags().StringP("columns", "c", "", "columns to display - [+]<title>:<jq query for content>||<title>:<jq query for content>")
-	rootCmd.PersistentFlags().StringP("output", "o", "", "output format (raw, json, yaml, table, csv, none, base64, text)")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "output format (raw, json, jsonl, yaml, table, csv, none, base64, text)")
 	rootCmd.PersistentFlags().StringP("out-file", "O", "", "redirect output to file")
 	rootCmd.PersistentFlags().Bool("single", false, "convert single entry lists to a single object")
+	rootCmd.PersistentFlags().Int("max-pages", read.DefaultMaxPages, "maximum number of pages fetched by listings - 0 for no limit")
+	rootCmd.PersistentFlags().Bool("all", false, "fetch all pages of listings, alias for --max-pages 0")
+	rootCmd.PersistentFlags().Int("page-size", 0, "number of items fetched per page by listings - the API default is used if 0")
 
 	rootCmd.PersistentFlags().Bool("no-upgrade", false, "do not check for new versions")
 	rootCmd.PersistentFlags().BoolP("yes", "y", false, "answer yes to all prompts")
@@ -103,7 +110,7 @@ func init() {
 	_ = rootCmd.PersistentFlags().MarkHidden("hooks")
 
 	_ = rootCmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
-		return []cobra.Completion{"raw", "json", "yaml", "table", "csv", "none", "base64", "text"}, cobra.ShellCompDirectiveDefault
+		return []cobra.Completion{"raw", "json", "jsonl", "yaml", "table", "csv", "none", "base64", "text"}, cobra.ShellCompDirectiveDefault
 	})
 
 	_ = rootCmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"net/http"
 	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -24,18 +25,64 @@ func loadProfile(cmd *cobra.Command) *profile.Profile {
 		opts = []profile.Option{profile.FromFile(prof, path), profile.MergeWith(profile.FromEnv())}
 	}
 	p, err := profile.New(opts...)
-	if err != nil {
+	switch {
+	case err != nil && replaying(cmd) != nil:
+		// credentials are not required to replay a cassette
+		return &profile.Profile{AccessKey: "replay", SecretKey: "replay", Region: "eu-west-2"}
+	case err != nil:
 		messages.ExitErr(err)
 	}
 	return p
 }
 
+var (
+	recorder *sdk.Recorder
+	replayer *sdk.Replayer
+)
+
+// recording returns the recorder used when --record is set.
+func recording(cmd *cobra.Command) *sdk.Recorder {
+	path, _ := cmd.Flags().GetString("record")
+	if path == "" {
+		return nil
+	}
+	if recorder == nil {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		recorder = sdk.NewRecorder(path, verbose)
+	}
+	return recorder
+}
+
+// replaying returns the replayer used when --replay is set.
+// The default transport is replaced, to serve all calls made by the OUTSCALE SDK.
+func replaying(cmd *cobra.Command) *sdk.Replayer {
+	path, _ := cmd.Flags().GetString("replay")
+	if path == "" {
+		return nil
+	}
+	if replayer == nil {
+		var err error
+		replayer, err = sdk.NewReplayer(path)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+		http.DefaultTransport = replayer
+	}
+	return replayer
+}
+
 func sdkOptions(cmd *cobra.Command) []middleware.MiddlewareChainOption {
 	ua := "octl/" + version.Version
 	opts := []middleware.MiddlewareChainOption{options.WithUseragent(ua)}
-	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+	// replayed calls are served by the default transport
+	_ = replaying(cmd)
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	switch rec := recording(cmd); {
+	case rec != nil:
+		opts = append(opts, options.WithLogging(rec))
+	case verbose:
 		opts = append(opts, options.WithLogging(sdk.VerboseLogger{}))
-	} else {
+	default:
 		opts = append(opts, options.WithoutLogging())
 	}
 	return opts
@@ -51,5 +98,10 @@ func awsOptions(cmd *cobra.Command) []config.LoadOptionsFunc {
 			oos.WithUseragent(ua),
 		)
 	}
+	if rep := replaying(cmd); rep != nil {
+		opts = append(opts, config.WithHTTPClient(&http.Client{Transport: rep}))
+	} else if rec := recording(cmd); rec != nil {
+		opts = append(opts, config.WithHTTPClient(&http.Client{Transport: rec.Transport(http.DefaultTransport)}))
+	}
 	return opts
 }
@@ -6,6 +6,7 @@ SPDX-License-Identifier: BSD-3-Clause
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -37,6 +38,25 @@ func init() {
 		return true
 	}, callOOS)
 	b.Build(storageCmd, nil)
+	storageCmd.AddCommand(storageSyncCmd)
+	for _, path := range [][]string{{"api", "PutObject"}, {"object", "put"}} {
+		cmd, _, err := storageCmd.Find(path)
+		if err != nil {
+			panic(err)
+		}
+		addMultipartFlags(cmd.Flags())
+	}
+	cmd, _, err := storageCmd.Find([]string{"object"})
+	if err != nil {
+		panic(err)
+	}
+	cmd.AddCommand(objectPresignCmd)
+	addRecursiveFlags(cmd)
+	cmd, _, err = storageCmd.Find([]string{"multipartupload"})
+	if err != nil {
+		panic(err)
+	}
+	cmd.AddCommand(abortStaleUploadsCmd)
 
 	runner.RegisterHook("auto-content-type", guessContentType)
 }
@@ -45,7 +65,11 @@ func callOOS(cmd *cobra.Command, args []string) {
 	debug.Println(cmd.Name() + " called")
 	p := loadProfile(cmd)
 	cl, err := oos.NewClient(cmd.Context(), p, awsOptions(cmd)...)
-	if err == nil {
+	done := false
+	if err == nil && cmd.Name() == "PutObject" {
+		done, err = putMultipart(cmd, cl)
+	}
+	if err == nil && !done {
 		err = runner.Run[*oos.Client, oos.Error](cmd, args, cl, config.For("storage"))
 	}
 	if err != nil {
@@ -71,19 +95,31 @@ func guessContentType(arg reflect.Value) {
 			messages.Info("cannot compute content-type")
 			return
 		}
-		body, err := io.ReadAll(io.LimitReader(seeker, 200))
-		if err != nil {
-			_, _ = seeker.Seek(0, io.SeekStart) // in case something was read...
-			messages.Info("cannot compute content-type: %v", err)
-			return
-		}
-		_, err = seeker.Seek(0, io.SeekStart)
-		if err != nil {
+		mime, err := detectContentType(seeker)
+		switch {
+		case errors.Is(err, errNotRewound):
 			messages.ExitErr(fmt.Errorf("cannot compute content-type: %w", err))
 			return
+		case err != nil:
+			messages.Info("cannot compute content-type: %v", err)
+			return
 		}
-		mime := mimetype.Detect(body)
-		messages.Info("detected mime-type: %s", mime.String())
-		po.ContentType = new(mime.String())
+		messages.Info("detected mime-type: %s", mime)
+		po.ContentType = new(mime)
+	}
+}
+
+var errNotRewound = errors.New("body cannot be rewound")
+
+// detectContentType detects the content type of a body from its first bytes, and rewinds the body.
+func detectContentType(body io.ReadSeeker) (string, error) {
+	head, err := io.ReadAll(io.LimitReader(body, 200))
+	if err != nil {
+		_, _ = body.Seek(0, io.SeekStart) // in case something was read...
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("%w: %w", errNotRewound, err)
 	}
+	return mimetype.Detect(head).String(), nil
 }
@@ -0,0 +1,430 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/flags"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oos"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxParts is the maximum number of parts of a multipart upload.
+const maxParts = 10000
+
+// addMultipartFlags adds the flags of multipart uploads to a command calling PutObject.
+func addMultipartFlags(fs *pflag.FlagSet) {
+	fs.Int("multipart-threshold", 100, "size in MiB above which a file is uploaded in multiple parts")
+	fs.Int("part-size", 16, "size in MiB of the parts of a multipart upload")
+	fs.Int("concurrency", 4, "maximum number of parts uploaded concurrently")
+	fs.Bool("resume", false, "resume an interrupted multipart upload")
+}
+
+// uploadState is the state of a multipart upload, stored to resume it if it is interrupted.
+// The state file is written each time a part is uploaded.
+type uploadState struct {
+	Bucket   string `json:"Bucket"`
+	Key      string `json:"Key"`
+	UploadId string `json:"UploadId"`
+	// File, Size and ModTime identify the uploaded file, which must not change before the upload is resumed.
+	File     string    `json:"File"`
+	Size     int64     `json:"Size"`
+	ModTime  time.Time `json:"ModTime"`
+	PartSize int64     `json:"PartSize"`
+	// Parts are the ETags of the uploaded parts, by part number.
+	Parts map[int32]string `json:"Parts"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// uploadStateDir returns the directory of the states of multipart uploads.
+func uploadStateDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("config dir: %w", err)
+	}
+	return filepath.Join(dir, "octl", "uploads"), nil
+}
+
+// uploadStatePath returns the path of the state of an upload to an object.
+func uploadStatePath(bucket, key string) (string, error) {
+	dir, err := uploadStateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(bucket + "/" + key)) //nolint:gosec
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// loadUploadState loads the state of an interrupted upload to an object, or returns nil if there is none.
+func loadUploadState(bucket, key string) (*uploadState, error) {
+	path, err := uploadStatePath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(path) //nolint:gosec
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("read upload state: %w", err)
+	}
+	st := &uploadState{path: path}
+	if err := json.Unmarshal(buf, st); err != nil {
+		return nil, fmt.Errorf("read upload state %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// record records an uploaded part, and writes the state file.
+func (st *uploadState) record(part int32, etag string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.Parts[part] = etag
+	return st.save()
+}
+
+func (st *uploadState) save() error {
+	if err := os.MkdirAll(filepath.Dir(st.path), 0o700); err != nil {
+		return fmt.Errorf("write upload state: %w", err)
+	}
+	buf, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("write upload state: %w", err)
+	}
+	// the state is replaced atomically, so that an interruption never leaves a truncated file
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return fmt.Errorf("write upload state: %w", err)
+	}
+	if err := os.Rename(tmp, st.path); err != nil {
+		return fmt.Errorf("write upload state: %w", err)
+	}
+	return nil
+}
+
+// remove removes the state file, once the upload is completed or aborted.
+func (st *uploadState) remove() error {
+	if err := os.Remove(st.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove upload state: %w", err)
+	}
+	return nil
+}
+
+// copyFields copies the fields of src to the fields of dst having the same name and type, both being struct pointers.
+func copyFields(dst, src any) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := range d.NumField() {
+		sf := s.FieldByName(d.Type().Field(i).Name)
+		if sf.IsValid() && sf.Type() == d.Field(i).Type() && d.Field(i).CanSet() {
+			d.Field(i).Set(sf)
+		}
+	}
+}
+
+// putMultipart uploads the body of a PutObject call in multiple parts, if it is a file larger than the threshold or if
+// --resume is set. It returns false if the body needs to be uploaded by PutObject.
+func putMultipart(cmd *cobra.Command, cl *oos.Client) (bool, error) {
+	resume, _ := cmd.Flags().GetBool("resume")
+	body := cmd.Flags().Lookup("Body")
+	if body == nil || !body.Changed {
+		if resume {
+			return true, errors.New("--resume needs a file to upload")
+		}
+		return false, nil
+	}
+	fi, err := os.Stat(body.Value.String())
+	if err != nil {
+		return true, fmt.Errorf("body: %w", err)
+	}
+	threshold, _ := cmd.Flags().GetInt("multipart-threshold")
+	if fi.Size() < int64(threshold)<<20 && !resume {
+		return false, nil
+	}
+
+	in := &s3.PutObjectInput{}
+	if err := runner.ToStruct(cmd, reflect.ValueOf(in), ""); err != nil {
+		return true, err
+	}
+	fd, ok := in.Body.(*os.File)
+	if !ok {
+		return true, errors.New("the body of a multipart upload must be a file")
+	}
+	bucket, key := lo.FromPtr(in.Bucket), lo.FromPtr(in.Key)
+	st, err := loadUploadState(bucket, key)
+	switch {
+	case err != nil:
+		return true, err
+	case resume && st == nil:
+		return true, fmt.Errorf("no interrupted upload of s3://%s/%s found", bucket, key)
+	case resume && (st.Size != fi.Size() || !st.ModTime.Equal(fi.ModTime())):
+		return true, fmt.Errorf("%s has been modified since the upload of s3://%s/%s was interrupted", fd.Name(), bucket, key)
+	case !resume && st != nil:
+		return true, fmt.Errorf("an upload of s3://%s/%s was interrupted - use --resume to continue it, or remove %s", bucket, key, st.path)
+	case !resume:
+		st, err = createUpload(cmd, cl, in, fi)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if err := uploadParts(cmd.Context(), cl, st, fd, max(concurrency, 1)); err != nil {
+		return true, fmt.Errorf("%w - run the same command with --resume to continue the upload", err)
+	}
+	parts := lo.MapToSlice(st.Parts, func(n int32, etag string) types.CompletedPart {
+		return types.CompletedPart{PartNumber: new(n), ETag: new(etag)}
+	})
+	slices.SortFunc(parts, func(a, b types.CompletedPart) int { return cmp.Compare(*a.PartNumber, *b.PartNumber) })
+	resp, err := cl.CompleteMultipartUpload(cmd.Context(), &s3.CompleteMultipartUploadInput{
+		Bucket: &bucket, Key: &key, UploadId: &st.UploadId, MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return true, fmt.Errorf("complete upload: %w", err)
+	}
+	if err := st.remove(); err != nil {
+		return true, err
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "yaml", "", nil, false, false)
+	if err == nil {
+		err = fmter.Format(cmd.Context(), os.Stdout, resp)
+	}
+	return true, err
+}
+
+// createUpload creates a multipart upload, with the parameters of a PutObject call, and its state file.
+func createUpload(cmd *cobra.Command, cl *oos.Client, in *s3.PutObjectInput, fi os.FileInfo) (*uploadState, error) {
+	partSize, _ := cmd.Flags().GetInt("part-size")
+	size := max(int64(partSize)<<20, 5<<20)
+	// parts are enlarged, by steps of 1 MiB, if the file would need too many parts
+	if parts := (fi.Size() + size - 1) / size; parts > maxParts {
+		size = ((fi.Size()+maxParts-1)/maxParts + 1<<20 - 1) &^ (1<<20 - 1)
+		debug.Println("part size raised to", byteSize(size))
+	}
+	if lo.FromPtr(in.ContentType) == "" {
+		if mime, err := detectContentType(in.Body.(io.ReadSeeker)); err == nil {
+			in.ContentType = &mime
+		}
+	}
+	create := &s3.CreateMultipartUploadInput{}
+	copyFields(create, in)
+	resp, err := cl.CreateMultipartUpload(cmd.Context(), create)
+	if err != nil {
+		return nil, fmt.Errorf("create upload: %w", err)
+	}
+	path, err := uploadStatePath(lo.FromPtr(in.Bucket), lo.FromPtr(in.Key))
+	if err != nil {
+		return nil, err
+	}
+	st := &uploadState{
+		Bucket: lo.FromPtr(in.Bucket), Key: lo.FromPtr(in.Key), UploadId: lo.FromPtr(resp.UploadId),
+		File: fi.Name(), Size: fi.Size(), ModTime: fi.ModTime(), PartSize: size,
+		Parts: map[int32]string{}, path: path,
+	}
+	return st, st.save()
+}
+
+// uploadParts uploads the parts which have not been uploaded yet, with at most concurrency concurrent uploads.
+func uploadParts(ctx context.Context, cl *oos.Client, st *uploadState, fd *os.File, concurrency int) error {
+	count := int32(max((st.Size+st.PartSize-1)/st.PartSize, 1))
+	progress := spinner.NewProgress()
+	task := progress.Add(fmt.Sprintf("Uploading %s to s3://%s/%s", st.File, st.Bucket, st.Key))
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		uploaded atomic.Int64
+		errs     = make([]error, count)
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	report := func() {
+		task.Set(spinner.Running, fmt.Sprintf("%d/%d parts - %s/%s",
+			len(st.Parts), count, byteSize(min(uploaded.Load(), st.Size)), byteSize(st.Size)))
+	}
+	st.mu.Lock()
+	uploaded.Store(int64(len(st.Parts)) * st.PartSize)
+	report()
+	st.mu.Unlock()
+	for n := int32(1); n <= count; n++ {
+		st.mu.Lock()
+		_, done := st.Parts[n]
+		st.mu.Unlock()
+		if done {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			off := int64(n-1) * st.PartSize
+			size := min(st.PartSize, st.Size-off)
+			resp, err := cl.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket: &st.Bucket, Key: &st.Key, UploadId: &st.UploadId, PartNumber: new(n),
+				Body: io.NewSectionReader(fd, off, size), ContentLength: new(size),
+			})
+			if err == nil {
+				err = st.record(n, lo.FromPtr(resp.ETag))
+			}
+			if err != nil {
+				errs[n-1] = fmt.Errorf("part %d: %w", n, err)
+				cancel()
+				return
+			}
+			uploaded.Add(size)
+			st.mu.Lock()
+			report()
+			st.mu.Unlock()
+		})
+	}
+	wg.Wait()
+	err := errors.Join(lo.Filter(errs, func(err error, _ int) bool { return err != nil && !errors.Is(err, context.Canceled) })...)
+	if err == nil {
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		task.Set(spinner.Failed, fmt.Sprintf("%d/%d parts", len(st.Parts), count))
+	} else {
+		task.Set(spinner.Succeeded, byteSize(st.Size))
+	}
+	progress.Stop()
+	return err
+}
+
+var abortStaleUploadsCmd = &cobra.Command{
+	Use:   "abort-stale",
+	Short: "Aborts the incomplete multipart uploads of a bucket, started before a date",
+	Long: `Aborts the incomplete multipart uploads of a bucket, started before a date, 7 days ago by default.
+
+The parts of incomplete uploads are stored, and billed, until the upload is completed or aborted. The state files of
+the aborted uploads, used by object put --resume, are removed.`,
+	Example: `octl storage multipartupload abort-stale --bucket my-bucket
+octl storage multipartupload abort-stale --bucket my-bucket --before -1d --prefix backups/ -y`,
+	Args: cobra.NoArgs,
+	Run:  abortStaleUploads,
+}
+
+func init() {
+	abortStaleUploadsCmd.Flags().String("bucket", "", "bucket of the uploads")
+	abortStaleUploadsCmd.Flags().String("before", "-7d", "abort the uploads started before this date, or offset (e.g. -1d, -12h)")
+	abortStaleUploadsCmd.Flags().String("prefix", "", "only abort the uploads of the keys starting with this prefix")
+	_ = abortStaleUploadsCmd.MarkFlagRequired("bucket")
+}
+
+// staleUpload is an incomplete multipart upload.
+type staleUpload struct {
+	Key       string    `json:"Key"`
+	UploadId  string    `json:"UploadId"`
+	Initiated time.Time `json:"Initiated"`
+	Error     string    `json:"Error,omitempty"`
+}
+
+var staleUploadColumns = config.Columns{
+	{Title: "Key", Content: ".Key"},
+	{Title: "UploadId", Content: ".UploadId"},
+	{Title: "Initiated", Content: ".Initiated"},
+}
+
+// listStaleUploads lists the uploads of a bucket started before a date.
+func listStaleUploads(ctx context.Context, cl *oos.Client, bucket, prefix string, before time.Time) ([]staleUpload, error) {
+	uploads := []staleUpload{}
+	in := &s3.ListMultipartUploadsInput{Bucket: &bucket, Prefix: &prefix}
+	for {
+		resp, err := cl.ListMultipartUploads(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("list uploads: %w", err)
+		}
+		for _, u := range resp.Uploads {
+			if initiated := lo.FromPtr(u.Initiated); initiated.Before(before) {
+				uploads = append(uploads, staleUpload{Key: lo.FromPtr(u.Key), UploadId: lo.FromPtr(u.UploadId), Initiated: initiated})
+			}
+		}
+		if !lo.FromPtr(resp.IsTruncated) {
+			return uploads, nil
+		}
+		in.KeyMarker, in.UploadIdMarker = resp.NextKeyMarker, resp.NextUploadIdMarker
+	}
+}
+
+func abortStaleUploads(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	bucket, _ := cmd.Flags().GetString("bucket")
+	prefix, _ := cmd.Flags().GetString("prefix")
+	offset, _ := cmd.Flags().GetString("before")
+	tv := flags.NewTimeValue()
+	if err := tv.Set(offset); err != nil {
+		messages.ExitErr(fmt.Errorf("--before: %w", err))
+	}
+	before, _ := tv.Value()
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	uploads, err := listStaleUploads(cmd.Context(), cl, bucket, prefix, before.Time)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if len(uploads) == 0 {
+		messages.Info("No upload started before %s.", before.Time.Format(time.RFC3339))
+		return
+	}
+	display := func(cmd *cobra.Command, args []string) {
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", staleUploadColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(uploads))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	alias.Confirm(config.ActionDelete, display, func(cmd *cobra.Command, args []string) {
+		var failed []staleUpload
+		for _, u := range uploads {
+			_, err := cl.AbortMultipartUpload(cmd.Context(), &s3.AbortMultipartUploadInput{Bucket: &bucket, Key: &u.Key, UploadId: &u.UploadId})
+			if err != nil {
+				u.Error = err.Error()
+				failed = append(failed, u)
+				continue
+			}
+			if st, err := loadUploadState(bucket, u.Key); err == nil && st != nil && st.UploadId == u.UploadId {
+				if err := st.remove(); err != nil {
+					debug.Println(err)
+				}
+			}
+		}
+		for _, u := range failed {
+			messages.Err("%s (%s): %s", u.Key, u.UploadId, u.Error)
+		}
+		if len(failed) > 0 {
+			messages.Exit(1, "%d of %d upload(s) not aborted", len(failed), len(uploads))
+		}
+	})(cmd, args)
+}
@@ -0,0 +1,126 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/flags"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oos"
+	"github.com/spf13/cobra"
+)
+
+var objectPresignCmd = &cobra.Command{
+	Use:   "presign key",
+	Short: "Generates a presigned URL, downloading or uploading an object without credentials",
+	Long: `Generates a presigned URL, downloading (GET) or uploading (PUT) an object without credentials, until it expires.
+
+The URL is signed with the credentials of the profile, without calling the API. A URL cannot be valid for more than
+7 days, and is no longer valid once the access key signing it is deleted.`,
+	Example: `octl storage object presign report.pdf --bucket my-bucket
+octl storage object presign report.pdf --bucket my-bucket --expires +2h -o json
+octl storage object presign upload.tar.gz --bucket my-bucket --method PUT --expires +30m`,
+	Args: cobra.ExactArgs(1),
+	Run:  objectPresign,
+}
+
+func init() {
+	objectPresignCmd.Flags().String("bucket", "", "bucket of the object")
+	objectPresignCmd.Flags().String("expires", "+1h", "expiration date, or offset (e.g. +2h, +1d)")
+	objectPresignCmd.Flags().String("method", http.MethodGet, "HTTP method allowed by the URL, GET or PUT")
+	_ = objectPresignCmd.MarkFlagRequired("bucket")
+	_ = objectPresignCmd.RegisterFlagCompletionFunc("method", cobra.FixedCompletions([]string{http.MethodGet, http.MethodPut}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// maxPresignExpiry is the maximum validity of a URL signed with SigV4.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// presignedURL is a presigned URL of an object.
+type presignedURL struct {
+	URL     string    `json:"URL"`
+	Method  string    `json:"Method"`
+	Expires time.Time `json:"Expires"`
+}
+
+var presignedURLColumns = config.Columns{
+	{Title: "Method", Content: ".Method"},
+	{Title: "Expires", Content: ".Expires"},
+	{Title: "URL", Content: ".URL"},
+}
+
+// presignObject returns a URL allowing a GET or a PUT of an object, during expires.
+func presignObject(ctx context.Context, cl *oos.Client, method, bucket, key string, expires time.Duration) (string, error) {
+	pcl := s3.NewPresignClient(cl.Client, s3.WithPresignExpires(expires))
+	var (
+		req *v4.PresignedHTTPRequest
+		err error
+	)
+	switch method {
+	case http.MethodGet:
+		req, err = pcl.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+	case http.MethodPut:
+		req, err = pcl.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: &bucket, Key: &key})
+	default:
+		return "", fmt.Errorf("unsupported method %q, use GET or PUT", method)
+	}
+	if err != nil {
+		return "", fmt.Errorf("presign: %w", err)
+	}
+	return req.URL, nil
+}
+
+func objectPresign(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	bucket, _ := cmd.Flags().GetString("bucket")
+	method, _ := cmd.Flags().GetString("method")
+	method = strings.ToUpper(method)
+	offset, _ := cmd.Flags().GetString("expires")
+	tv := flags.NewTimeValue()
+	if err := tv.Set(offset); err != nil {
+		messages.ExitErr(fmt.Errorf("--expires: %w", err))
+	}
+	expires, _ := tv.Value()
+	// the expiry is signed in seconds
+	validity := time.Until(expires.Time).Round(time.Second)
+	switch {
+	case validity <= 0:
+		messages.ExitErr(fmt.Errorf("--expires: %s is in the past", expires.Time.Format(time.RFC3339)))
+	case validity > maxPresignExpiry:
+		messages.ExitErr(errors.New("--expires: a presigned URL cannot be valid for more than 7 days"))
+	}
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	url, err := presignObject(cmd.Context(), cl, method, bucket, args[0], validity)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if out, _ := cmd.Flags().GetString("output"); out == "" {
+		fmt.Println(url)
+		return
+	}
+	res := presignedURL{URL: url, Method: method, Expires: time.Now().Add(validity).UTC().Truncate(time.Second)}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "", "", presignedURLColumns, false, false)
+	if err == nil {
+		err = fmter.Format(cmd.Context(), os.Stdout, res)
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+}
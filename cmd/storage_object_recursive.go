@@ -0,0 +1,269 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/output/read"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oos"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// maxDeleteKeys is the maximum number of keys deleted by a DeleteObjects call.
+const maxDeleteKeys = 1000
+
+// addRecursiveFlags adds --recursive to the object commands, running them on every key under a prefix.
+func addRecursiveFlags(objectCmd *cobra.Command) {
+	for _, r := range []struct {
+		name, usage string
+		run         func(cmd *cobra.Command, args []string)
+	}{
+		{"list", "list every object under --prefix, fetching all pages", nil},
+		{"delete", "delete every object under the keys, used as prefixes", deleteRecursive},
+		{"copy", "copy every object under bucket_src/key_src to key_dst, used as prefixes", copyRecursive},
+		{"download", "download every object under the key, used as a prefix, to the directory set by --out-file", downloadRecursive},
+	} {
+		cmd, _, err := objectCmd.Find([]string{r.name})
+		if err != nil {
+			panic(err)
+		}
+		cmd.Flags().BoolP("recursive", "r", false, r.usage)
+		// the other transfers are iterative aliases, which already have --parallel
+		if r.name == "copy" {
+			cmd.Flags().Int(alias.ParallelFlag, 0, "number of concurrent copies with --recursive")
+		}
+		next, run := cmd.Run, r.run
+		if run == nil {
+			run = listAll(next)
+		}
+		cmd.Run = func(cmd *cobra.Command, args []string) {
+			if recursive, _ := cmd.Flags().GetBool("recursive"); recursive {
+				run(cmd, args)
+				return
+			}
+			next(cmd, args)
+		}
+	}
+}
+
+// listAll returns a run function fetching all the pages of a listing, a listing without delimiter being recursive.
+func listAll(next func(cmd *cobra.Command, args []string)) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		// --recursive is not a flag of the aliased call
+		cmd.Flags().Lookup("recursive").Changed = false
+		_ = cmd.Flags().Set("all", "true")
+		if delimiter := cmd.Flags().Lookup("delimiter"); delimiter != nil && delimiter.Changed {
+			_ = delimiter.Value.Set("")
+			delimiter.Changed = false
+		}
+		next(cmd, args)
+	}
+}
+
+// listPrefix lists every object of a location, using the pager of listings.
+func listPrefix(ctx context.Context, cl *oos.Client, l syncLocation) ([]types.Object, error) {
+	fetch := read.FetchPage{
+		Method: reflect.ValueOf(cl.Client).MethodByName("ListObjectsV2"),
+		Args:   []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(&s3.ListObjectsV2Input{Bucket: &l.bucket, Prefix: &l.prefix})},
+		Quiet:  true,
+	}
+	objects := []types.Object{}
+	for res := range read.NewPaginated("Contents", 0, 0).Read(ctx, fetch) {
+		switch {
+		case res.Error != nil:
+			return nil, fmt.Errorf("list %s: %w", l, res.Error)
+		case res.SingleEntry:
+			// an empty listing has no content
+			continue
+		}
+		if obj, ok := res.Ok.(types.Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// objectPrefix returns the location of the objects under a key of a bucket, used as a prefix.
+func objectPrefix(bucket, key string) (syncLocation, error) {
+	return parseLocation("s3://" + bucket + "/" + key)
+}
+
+// transferActions returns the actions transferring the objects of a location, by key relative to the location.
+func transferActions(l syncLocation, objects []types.Object, action string) []syncAction {
+	actions := []syncAction{}
+	for _, obj := range objects {
+		rel, ok := l.relativeKey(lo.FromPtr(obj.Key))
+		if !ok {
+			continue
+		}
+		actions = append(actions, syncAction{Action: action, Key: rel, Size: lo.FromPtr(obj.Size)})
+	}
+	return actions
+}
+
+// transferRecursive runs transfers, and exits with the list of failed transfers if any.
+func transferRecursive(cmd *cobra.Command, cl *oos.Client, title string, src, dst syncLocation, actions []syncAction) {
+	if len(actions) == 0 {
+		messages.Info("No object found under %s.", src)
+		return
+	}
+	parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+	failed := runSync(cmd.Context(), cl, title, src, dst, actions, lo.Ternary(parallel > 0, parallel, 8))
+	exitFailed(cmd, failed, len(actions), "object(s) failed")
+}
+
+func copyRecursive(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " recursive called")
+	if len(args) != 2 {
+		messages.ExitErr(fmt.Errorf("copy needs a source bucket_src/key_src and a destination key_dst, got %d arg(s)", len(args)))
+	}
+	bucket, _ := cmd.Flags().GetString("bucket")
+	src, err := parseLocation("s3://" + args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	dst, err := objectPrefix(bucket, args[1])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	objects, err := listPrefix(cmd.Context(), cl, src)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	transferRecursive(cmd, cl, fmt.Sprintf("Copying %s to %s", src, dst), src, dst, transferActions(src, objects, "copy"))
+}
+
+func downloadRecursive(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " recursive called")
+	if len(args) != 1 {
+		messages.ExitErr(fmt.Errorf("download needs a single key, got %d arg(s)", len(args)))
+	}
+	bucket, _ := cmd.Flags().GetString("bucket")
+	src, err := objectPrefix(bucket, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	dir, _ := cmd.Flags().GetString("out-file")
+	dst, err := parseLocation(lo.CoalesceOrEmpty(dir, "."))
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	objects, err := listPrefix(cmd.Context(), cl, src)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	transferRecursive(cmd, cl, fmt.Sprintf("Downloading %s to %s", src, dst), src, dst, transferActions(src, objects, "download"))
+}
+
+// prefixSummary is the number and the total size of the objects under a prefix.
+type prefixSummary struct {
+	Prefix  string `json:"Prefix"`
+	Objects int    `json:"Objects"`
+	Size    string `json:"Size"`
+}
+
+var prefixSummaryColumns = config.Columns{
+	{Title: "Prefix", Content: ".Prefix"},
+	{Title: "Objects", Content: ".Objects"},
+	{Title: "Size", Content: ".Size"},
+}
+
+// deleteBatches deletes objects, by batches of maxDeleteKeys keys, and returns the failed deletions.
+func deleteBatches(ctx context.Context, cl *oos.Client, bucket string, keys []string) []syncResult {
+	progress := spinner.NewProgress()
+	task := progress.Add(fmt.Sprintf("Deleting %d object(s) of %s", len(keys), bucket))
+	failed := []syncResult{}
+	for i, batch := range lo.Chunk(keys, maxDeleteKeys) {
+		task.Set(spinner.Running, fmt.Sprintf("%d/%d", i*maxDeleteKeys, len(keys)))
+		ids := lo.Map(batch, func(key string, _ int) types.ObjectIdentifier { return types.ObjectIdentifier{Key: new(key)} })
+		resp, err := cl.DeleteObjects(ctx, &s3.DeleteObjectsInput{Bucket: &bucket, Delete: &types.Delete{Objects: ids, Quiet: new(true)}})
+		if err != nil {
+			failed = append(failed, lo.Map(batch, func(key string, _ int) syncResult {
+				return syncResult{Action: "delete", Key: key, Error: err.Error()}
+			})...)
+			continue
+		}
+		for _, e := range resp.Errors {
+			failed = append(failed, syncResult{Action: "delete", Key: lo.FromPtr(e.Key), Error: lo.FromPtr(e.Message)})
+		}
+	}
+	detail := fmt.Sprintf("%d object(s)", len(keys)-len(failed))
+	if len(failed) > 0 {
+		task.Set(spinner.Failed, fmt.Sprintf("%s - %d failure(s)", detail, len(failed)))
+	} else {
+		task.Set(spinner.Succeeded, detail)
+	}
+	progress.Stop()
+	return failed
+}
+
+func deleteRecursive(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " recursive called")
+	bucket, _ := cmd.Flags().GetString("bucket")
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	var (
+		keys    []string
+		summary []prefixSummary
+	)
+	cancel := spinner.Run(cmd.Context(), "Listing objects...")
+	for _, arg := range args {
+		l, err := objectPrefix(bucket, arg)
+		if err == nil {
+			var objects []types.Object
+			objects, err = listPrefix(cmd.Context(), cl, l)
+			keys = append(keys, lo.Map(objects, func(obj types.Object, _ int) string { return lo.FromPtr(obj.Key) })...)
+			size := lo.SumBy(objects, func(obj types.Object) int64 { return lo.FromPtr(obj.Size) })
+			summary = append(summary, prefixSummary{Prefix: l.String(), Objects: len(objects), Size: byteSize(size)})
+		}
+		if err != nil {
+			cancel()
+			messages.ExitErr(err)
+		}
+	}
+	cancel()
+	// prefixes may overlap
+	keys = lo.Uniq(keys)
+	if len(keys) == 0 {
+		messages.Info("No object found.")
+		return
+	}
+	display := func(cmd *cobra.Command, args []string) {
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", prefixSummaryColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(summary))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	alias.Confirm(config.ActionDelete, display, func(cmd *cobra.Command, args []string) {
+		exitFailed(cmd, deleteBatches(cmd.Context(), cl, bucket, keys), len(keys), "object(s) failed")
+	})(cmd, args)
+}
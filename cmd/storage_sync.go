@@ -0,0 +1,531 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oos"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var storageSyncCmd = &cobra.Command{
+	Use:   "sync source destination",
+	Short: "Synchronizes a local directory with a bucket, or two buckets",
+	Long: `Synchronizes a local directory with a bucket, or two buckets, a bucket being written as s3://bucket/prefix.
+
+Only the files and objects which are missing or which differ are transferred. Two files differ if their sizes differ,
+or if the source has been modified after the destination and their content differs, according to their ETag. With
+--delete, the files and objects of the destination which are missing from the source are deleted.
+
+--include and --exclude filter files and objects by glob patterns. A pattern without / is matched against the name of
+files, and a pattern with / against their path, relative to the directory or to the prefix. Files which are excluded
+are neither transferred nor deleted.`,
+	Example: `octl storage sync ./site s3://my-bucket/www --delete
+octl storage sync s3://my-bucket/backups ./backups --include '*.tar.gz'
+octl storage sync s3://my-bucket s3://my-replica --exclude 'tmp/*' --dry-run`,
+	Args: cobra.ExactArgs(2),
+	Run:  syncStorage,
+}
+
+func init() {
+	storageSyncCmd.Flags().Bool("delete", false, "delete the files and objects of the destination which are missing from the source")
+	storageSyncCmd.Flags().StringArray("include", nil, "only synchronize the files matching this glob pattern")
+	storageSyncCmd.Flags().StringArray("exclude", nil, "do not synchronize the files matching this glob pattern")
+	storageSyncCmd.Flags().Bool("dry-run", false, "display the transfers and the deletions, without doing them")
+	storageSyncCmd.Flags().Int(alias.ParallelFlag, 8, "maximum number of concurrent transfers")
+}
+
+// syncLocation is the source or the destination of a sync, a local directory or a bucket prefix.
+type syncLocation struct {
+	dir    string
+	bucket string
+	prefix string
+}
+
+// parseLocation parses a local path, or a bucket prefix written as s3://bucket/prefix.
+func parseLocation(s string) (syncLocation, error) {
+	rest, found := strings.CutPrefix(s, "s3://")
+	if !found {
+		return syncLocation{dir: filepath.Clean(s)}, nil
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return syncLocation{}, fmt.Errorf("invalid location %q: missing bucket", s)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return syncLocation{bucket: bucket, prefix: prefix}, nil
+}
+
+func (l syncLocation) remote() bool {
+	return l.bucket != ""
+}
+
+func (l syncLocation) String() string {
+	if l.remote() {
+		return "s3://" + l.bucket + "/" + l.prefix
+	}
+	return l.dir
+}
+
+// path returns the path of a file, rel being relative to the directory.
+// Keys escaping the directory, e.g. ../.bashrc or /etc/passwd, are rejected.
+func (l syncLocation) path(rel string) (string, error) {
+	local := filepath.FromSlash(rel)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("%q is not a path under %s", rel, l.dir)
+	}
+	return filepath.Join(l.dir, local), nil
+}
+
+// relativeKey returns the key of an object relative to the location, and false if the object is not a file, e.g. a folder
+// created by a console, i.e. an empty object ending with /.
+func (l syncLocation) relativeKey(key string) (string, bool) {
+	rel := strings.TrimPrefix(key, l.prefix)
+	return rel, rel != "" && !strings.HasSuffix(rel, "/")
+}
+
+// syncEntry is a file or an object found in a location.
+type syncEntry struct {
+	size    int64
+	modTime time.Time
+	etag    string
+	// path is the path of a local file, whose ETag is computed when needed.
+	path string
+}
+
+// sum returns the MD5 checksum of an entry, as the ETag of an object uploaded in a single part, or an empty string if
+// it is unknown.
+func (e *syncEntry) sum() string {
+	if e.etag == "" && e.path != "" {
+		e.etag = fileSum(e.path)
+	}
+	// the ETag of an object uploaded in multiple parts is not a checksum of its content
+	if strings.Contains(e.etag, "-") {
+		return ""
+	}
+	return e.etag
+}
+
+// fileSum returns the MD5 checksum of a file, or an empty string if it cannot be read.
+func fileSum(p string) string {
+	fd, err := os.Open(p) //nolint:gosec
+	if err != nil {
+		debug.Println("unable to compute checksum:", err)
+		return ""
+	}
+	defer fd.Close() //nolint
+	h := md5.New()   //nolint:gosec
+	if _, err := io.Copy(h, fd); err != nil {
+		debug.Println("unable to compute checksum:", err)
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// differs returns true if the destination entry needs to be replaced by the source entry.
+func (e *syncEntry) differs(dst *syncEntry) bool {
+	if e.size != dst.size {
+		return true
+	}
+	if !e.modTime.After(dst.modTime) {
+		return false
+	}
+	src, cur := e.sum(), dst.sum()
+	return src == "" || cur == "" || src != cur
+}
+
+// syncFilter filters files and objects using glob patterns.
+type syncFilter struct {
+	include, exclude []string
+}
+
+func newSyncFilter(include, exclude []string) (syncFilter, error) {
+	for _, pattern := range slices.Concat(include, exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return syncFilter{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return syncFilter{include: include, exclude: exclude}, nil
+}
+
+// match returns true if a file, rel being relative to its location, is synchronized.
+func (f syncFilter) match(rel string) bool {
+	matches := func(pattern string) bool {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+	if len(f.include) > 0 && !slices.ContainsFunc(f.include, matches) {
+		return false
+	}
+	return !slices.ContainsFunc(f.exclude, matches)
+}
+
+// listLocation lists the files or objects of a location, by path relative to the location.
+// A missing local directory is empty if it is the destination, and an error if it is the source.
+func listLocation(ctx context.Context, cl *oos.Client, l syncLocation, filter syncFilter, destination bool) (map[string]*syncEntry, error) {
+	entries := map[string]*syncEntry{}
+	if !l.remote() {
+		err := filepath.WalkDir(l.dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if destination && errors.Is(err, fs.ErrNotExist) && p == l.dir {
+					// the destination directory is created by the sync
+					return filepath.SkipAll
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(l.dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if !filter.match(rel) {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			entries[rel] = &syncEntry{size: fi.Size(), modTime: fi.ModTime(), path: p}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", l, err)
+		}
+		return entries, nil
+	}
+	pages := s3.NewListObjectsV2Paginator(cl.Client, &s3.ListObjectsV2Input{Bucket: &l.bucket, Prefix: &l.prefix})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", l, err)
+		}
+		for _, obj := range page.Contents {
+			rel, ok := l.relativeKey(lo.FromPtr(obj.Key))
+			if !ok || !filter.match(rel) {
+				continue
+			}
+			entries[rel] = &syncEntry{
+				size:    lo.FromPtr(obj.Size),
+				modTime: lo.FromPtr(obj.LastModified),
+				etag:    strings.Trim(lo.FromPtr(obj.ETag), `"`),
+			}
+		}
+	}
+	return entries, nil
+}
+
+// syncAction is a transfer or a deletion.
+type syncAction struct {
+	Action string `json:"Action"`
+	Key    string `json:"Key"`
+	Size   int64  `json:"Size"`
+}
+
+var syncColumns = config.Columns{
+	{Title: "Action", Content: ".Action"},
+	{Title: "Key", Content: ".Key"},
+	{Title: "Size", Content: ".Size"},
+}
+
+// planSync returns the actions needed for the destination to match the source, deletions being last.
+func planSync(src, dst syncLocation, srcEntries, dstEntries map[string]*syncEntry, del bool) []syncAction {
+	transfer := "copy"
+	switch {
+	case !src.remote():
+		transfer = "upload"
+	case !dst.remote():
+		transfer = "download"
+	}
+	actions := []syncAction{}
+	for rel, e := range srcEntries {
+		if cur, found := dstEntries[rel]; !found || e.differs(cur) {
+			actions = append(actions, syncAction{Action: transfer, Key: rel, Size: e.size})
+		}
+	}
+	if del {
+		for rel, e := range dstEntries {
+			if _, found := srcEntries[rel]; !found {
+				actions = append(actions, syncAction{Action: "delete", Key: rel, Size: e.size})
+			}
+		}
+	}
+	slices.SortFunc(actions, func(a, b syncAction) int {
+		return cmp.Or(cmp.Compare(lo.Ternary(a.Action == "delete", 1, 0), lo.Ternary(b.Action == "delete", 1, 0)), cmp.Compare(a.Key, b.Key))
+	})
+	return actions
+}
+
+// syncResult is an action which has failed.
+type syncResult struct {
+	Action string `json:"Action"`
+	Key    string `json:"Key"`
+	Error  string `json:"Error"`
+}
+
+var syncResultColumns = config.Columns{
+	{Title: "Action", Content: ".Action"},
+	{Title: "Key", Content: ".Key"},
+	{Title: "Error", Content: ".Error"},
+}
+
+// runSync runs the actions, displayed as title, with at most parallel concurrent actions, and returns the failed actions.
+func runSync(ctx context.Context, cl *oos.Client, title string, src, dst syncLocation, actions []syncAction, parallel int) []syncResult {
+	progress := spinner.NewProgress()
+	task := progress.Add(title)
+	total := lo.SumBy(actions, func(a syncAction) int64 { return lo.Ternary(a.Action == "delete", 0, a.Size) })
+	var (
+		wg                sync.WaitGroup
+		sem               = make(chan struct{}, parallel)
+		done, transferred atomic.Int64
+		mu                sync.Mutex
+		failed            []syncResult
+	)
+	report := func() {
+		task.Set(spinner.Running, fmt.Sprintf("%d/%d - %s/%s", done.Load(), len(actions), byteSize(transferred.Load()), byteSize(total)))
+	}
+	report()
+	for _, a := range actions {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			err := syncOne(ctx, cl, src, dst, a)
+			if err != nil {
+				mu.Lock()
+				failed = append(failed, syncResult{Action: a.Action, Key: a.Key, Error: err.Error()})
+				mu.Unlock()
+			} else if a.Action != "delete" {
+				transferred.Add(a.Size)
+			}
+			done.Add(1)
+			report()
+		})
+	}
+	wg.Wait()
+	detail := fmt.Sprintf("%d file(s), %s", len(actions)-len(failed), byteSize(transferred.Load()))
+	if len(failed) > 0 {
+		task.Set(spinner.Failed, fmt.Sprintf("%s - %d failure(s)", detail, len(failed)))
+	} else {
+		task.Set(spinner.Succeeded, detail)
+	}
+	progress.Stop()
+	slices.SortFunc(failed, func(a, b syncResult) int { return cmp.Compare(a.Key, b.Key) })
+	return failed
+}
+
+// syncOne runs a single action.
+func syncOne(ctx context.Context, cl *oos.Client, src, dst syncLocation, a syncAction) error {
+	switch a.Action {
+	case "upload":
+		p, err := src.path(a.Key)
+		if err != nil {
+			return err
+		}
+		return uploadFile(ctx, cl, p, dst.bucket, dst.prefix+a.Key)
+	case "download":
+		p, err := dst.path(a.Key)
+		if err != nil {
+			return err
+		}
+		return downloadObject(ctx, cl, src.bucket, src.prefix+a.Key, p)
+	case "copy":
+		source := (&url.URL{Path: src.bucket + "/" + src.prefix + a.Key}).EscapedPath()
+		_, err := cl.CopyObject(ctx, &s3.CopyObjectInput{Bucket: &dst.bucket, Key: new(dst.prefix + a.Key), CopySource: &source})
+		return err
+	case "delete":
+		if !dst.remote() {
+			p, err := dst.path(a.Key)
+			if err != nil {
+				return err
+			}
+			return os.Remove(p)
+		}
+		_, err := cl.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: &dst.bucket, Key: new(dst.prefix + a.Key)})
+		return err
+	}
+	return fmt.Errorf("unknown action %q", a.Action)
+}
+
+// uploadFile uploads a file, its content type being detected as with object put.
+func uploadFile(ctx context.Context, cl *oos.Client, p, bucket, key string) error {
+	fd, err := os.Open(p) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer fd.Close() //nolint
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	mime, err := detectContentType(fd)
+	if err != nil {
+		return fmt.Errorf("content type: %w", err)
+	}
+	_, err = cl.PutObject(ctx, &s3.PutObjectInput{Bucket: &bucket, Key: &key, Body: fd, ContentLength: new(fi.Size()), ContentType: &mime})
+	return err
+}
+
+// downloadObject downloads an object to a file, whose modification time is set to the one of the object.
+// The object is written to a temporary file first, so that a failed download does not leave a truncated file.
+func downloadObject(ctx context.Context, cl *oos.Client, bucket, key, p string) error {
+	resp, err := cl.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint
+	_, err = io.Copy(tmp, resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return err
+	}
+	if mtime := lo.FromPtr(resp.LastModified); !mtime.IsZero() {
+		return os.Chtimes(p, mtime, mtime)
+	}
+	return nil
+}
+
+// byteSize formats a number of bytes, using binary units.
+func byteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func syncStorage(cmd *cobra.Command, args []string) {
+	debug.Println(cmd.Name() + " called")
+	src, err := parseLocation(args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	dst, err := parseLocation(args[1])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if !src.remote() && !dst.remote() {
+		messages.ExitErr(errors.New("at least one location must be a bucket, written as s3://bucket/prefix"))
+	}
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	filter, err := newSyncFilter(include, exclude)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	cl, err := oos.NewClient(cmd.Context(), loadProfile(cmd), awsOptions(cmd)...)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+
+	cancel := spinner.Run(cmd.Context(), "Comparing files...")
+	srcEntries, err := listLocation(cmd.Context(), cl, src, filter, false)
+	var dstEntries map[string]*syncEntry
+	if err == nil {
+		dstEntries, err = listLocation(cmd.Context(), cl, dst, filter, true)
+	}
+	var actions []syncAction
+	if err == nil {
+		del, _ := cmd.Flags().GetBool("delete")
+		actions = planSync(src, dst, srcEntries, dstEntries, del)
+	}
+	cancel()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+
+	display := func(cmd *cobra.Command, args []string) {
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", syncColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(actions))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		display(cmd, args)
+		return
+	}
+	if len(actions) == 0 {
+		messages.Info("%s is up to date.", dst)
+		return
+	}
+	run := func(cmd *cobra.Command, args []string) {
+		parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+		failed := runSync(cmd.Context(), cl, fmt.Sprintf("Synchronizing %s to %s", src, dst), src, dst, actions, max(parallel, 1))
+		exitFailed(cmd, failed, len(actions), "file(s) not synchronized")
+	}
+	// deleting files is confirmed, as with other deletions
+	if slices.ContainsFunc(actions, func(a syncAction) bool { return a.Action == "delete" }) {
+		alias.Confirm(config.ActionDelete, display, run)(cmd, args)
+		return
+	}
+	run(cmd, args)
+}
+
+// exitFailed reports the failed actions on stderr, and exits if any, failure describing the failed actions.
+func exitFailed(cmd *cobra.Command, failed []syncResult, total int, failure string) {
+	if len(failed) == 0 {
+		return
+	}
+	tbl := format.Tabular{Columns: syncResultColumns, Formatter: format.TableFormatter{}}
+	if err := tbl.Format(cmd.Context(), os.Stderr, failed); err != nil {
+		messages.ExitErr(err)
+	}
+	messages.Exit(1, "%d of %d %s", len(failed), total, failure)
+}
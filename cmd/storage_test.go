@@ -9,6 +9,8 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +19,8 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/outscale/octl/cmd"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -401,3 +405,200 @@ func TestObjectTagging(t *testing.T) {
 		assert.Empty(t, resp.TagSet)
 	})
 }
+
+func TestMockStorage(t *testing.T) {
+	_, args := mockArgs(t)
+
+	path := file(t, "object.txt", hello)
+	_ = run(t, args("storage", "bucket", "create", "--bucket", "bucket"), nil)
+	_ = run(t, args("storage", "object", "put", "object.txt", "--bucket", "bucket", "--body", path), nil)
+
+	var lres s3.ListObjectsV2Output
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "-o", "raw"), nil, &lres)
+	require.Len(t, lres.Contents, 1)
+	assert.Equal(t, "object.txt", *lres.Contents[0].Key)
+
+	content := run(t, args("storage", "object", "download", "object.txt", "--bucket", "bucket"), nil)
+	assert.Equal(t, hello, string(content))
+	t.Run("Failed parallel runs are reported with their error", func(t *testing.T) {
+		stderr, err := tryStderr(t.Context(), args("storage", "object", "describe", "object.txt", "missing.txt", "--bucket", "bucket", "--parallel", "2"), nil)
+		require.Error(t, err)
+		assert.Regexp(t, `missing\.txt\s+failed\s+\S`, string(stderr))
+		assert.Regexp(t, `object\.txt\s+succeeded`, string(stderr))
+		assert.Contains(t, string(stderr), "1 of 2 run(s) failed")
+	})
+
+	_ = run(t, args("storage", "object", "del", "object.txt", "--bucket", "bucket", "-y"), nil)
+	_ = run(t, args("storage", "bucket", "del", "bucket", "-y"), nil)
+}
+
+func TestMockStorageSync(t *testing.T) {
+	_, args := mockArgs(t)
+	_ = run(t, args("storage", "bucket", "create", "--bucket", "bucket"), nil)
+	dir := filepath.Dir(file(t, "index.html", "<html></html>"))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "css"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "css", "site.css"), []byte("body {}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte(hello), 0o600))
+
+	var actions []map[string]any
+	runJSON(t, args("storage", "sync", dir, "s3://bucket/www", "--exclude", "*.log", "--dry-run", "-o", "json"), nil, &actions)
+	assert.Len(t, actions, 2)
+	_ = run(t, args("storage", "sync", dir, "s3://bucket/www", "--exclude", "*.log"), nil)
+	var lres s3.ListObjectsV2Output
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Equal(t, []string{"www/css/site.css", "www/index.html"}, lo.Map(lres.Contents, func(o types.Object, _ int) string { return *o.Key }))
+
+	out := t.TempDir()
+	_ = run(t, args("storage", "sync", "s3://bucket/www", out), nil)
+	content, err := os.ReadFile(filepath.Join(out, "css", "site.css"))
+	require.NoError(t, err)
+	assert.Equal(t, "body {}", string(content))
+	runJSON(t, args("storage", "sync", "s3://bucket/www", out, "--dry-run", "-o", "json"), nil, &actions)
+	assert.Empty(t, actions)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "index.html")))
+	_ = run(t, args("storage", "sync", dir, "s3://bucket/www", "--exclude", "*.log", "--delete", "-y"), nil)
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Len(t, lres.Contents, 1)
+
+	runWithError(t, args("storage", "sync", filepath.Join(dir, "missing"), "s3://bucket/www", "--delete", "-y"), nil)
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Len(t, lres.Contents, 1)
+}
+
+func TestMockStorageMultipart(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	_, args := mockArgs(t)
+	_ = run(t, args("storage", "bucket", "create", "--bucket", "bucket"), nil)
+	path := file(t, "backup.txt", hello)
+	_ = run(t, args("storage", "object", "put", "backup.txt", "--bucket", "bucket", "--body", path, "--multipart-threshold", "0"), nil)
+	content := run(t, args("storage", "object", "download", "backup.txt", "--bucket", "bucket"), nil)
+	assert.Equal(t, hello, string(content))
+	runWithError(t, args("storage", "object", "put", "other.txt", "--bucket", "bucket", "--body", path, "--resume"), nil)
+
+	_ = run(t, args("storage", "api", "CreateMultipartUpload", "--Bucket", "bucket", "--Key", "stale.txt"), nil)
+	var lres s3.ListMultipartUploadsOutput
+	runJSON(t, args("storage", "api", "ListMultipartUploads", "--Bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Len(t, lres.Uploads, 1)
+	_ = run(t, args("storage", "multipartupload", "abort-stale", "--bucket", "bucket", "-y"), nil)
+	runJSON(t, args("storage", "api", "ListMultipartUploads", "--Bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Len(t, lres.Uploads, 1)
+	_ = run(t, args("storage", "multipartupload", "abort-stale", "--bucket", "bucket", "--before", "+1h", "-y"), nil)
+	runJSON(t, args("storage", "api", "ListMultipartUploads", "--Bucket", "bucket", "-o", "raw"), nil, &lres)
+	assert.Empty(t, lres.Uploads)
+}
+
+func TestMockStoragePresign(t *testing.T) {
+	_, args := mockArgs(t)
+	_ = run(t, args("storage", "bucket", "create", "--bucket", "bucket"), nil)
+	url := strings.TrimSpace(string(run(t, args("storage", "object", "presign", "hello.txt", "--bucket", "bucket", "--method", "PUT"), nil)))
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPut, url, strings.NewReader(hello))
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var presigned struct {
+		URL     string
+		Method  string
+		Expires time.Time
+	}
+	runJSON(t, args("storage", "object", "presign", "hello.txt", "--bucket", "bucket", "--expires", "+2h", "-o", "json"), nil, &presigned)
+	assert.Equal(t, http.MethodGet, presigned.Method)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), presigned.Expires, time.Minute)
+	req, err = http.NewRequestWithContext(t.Context(), http.MethodGet, presigned.URL, nil)
+	require.NoError(t, err)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close() //nolint
+	content, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, hello, string(content))
+
+	runWithError(t, args("storage", "object", "presign", "hello.txt", "--bucket", "bucket", "--expires", "+8d"), nil)
+	runWithError(t, args("storage", "object", "presign", "hello.txt", "--bucket", "bucket", "--expires", "-1h"), nil)
+	runWithError(t, args("storage", "object", "presign", "hello.txt", "--bucket", "bucket", "--method", "DELETE"), nil)
+}
+
+func TestMockStorageRecursive(t *testing.T) {
+	_, args := mockArgs(t)
+	_ = run(t, args("storage", "bucket", "create", "--bucket", "bucket"), nil)
+	path := file(t, "hello.txt", hello)
+	for _, key := range []string{"logs/a.txt", "logs/2026/b.txt", "logs/2026/c.txt", "logsother.txt"} {
+		_ = run(t, args("storage", "object", "put", key, "--bucket", "bucket", "--body", path), nil)
+	}
+	var lres []types.Object
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "--prefix", "logs/", "--recursive", "--page-size", "1", "-o", "json"), nil, &lres)
+	assert.Len(t, lres, 3)
+
+	dir := t.TempDir()
+	_ = run(t, args("storage", "object", "download", "logs", "--bucket", "bucket", "--recursive", "-O", dir), nil)
+	content, err := os.ReadFile(filepath.Join(dir, "2026", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, hello, string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "other.txt"))
+
+	_ = run(t, args("storage", "object", "copy", "bucket/logs", "archive", "--bucket", "bucket", "--recursive"), nil)
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "--prefix", "archive/", "--recursive", "-o", "json"), nil, &lres)
+	assert.Equal(t, []string{"archive/2026/b.txt", "archive/2026/c.txt", "archive/a.txt"}, lo.Map(lres, func(o types.Object, _ int) string { return *o.Key }))
+
+	runWithError(t, args("storage", "object", "delete", "logs", "archive", "--bucket", "bucket", "--recursive"), nil)
+	_ = run(t, args("storage", "object", "delete", "logs", "archive", "--bucket", "bucket", "--recursive", "-y"), nil)
+	runJSON(t, args("storage", "object", "list", "--bucket", "bucket", "--recursive", "-o", "json"), nil, &lres)
+	assert.Equal(t, []string{"logsother.txt"}, lo.Map(lres, func(o types.Object, _ int) string { return *o.Key }))
+
+	// keys escaping the directory are not downloaded
+	_ = run(t, args("storage", "object", "put", "logs/../../escape.txt", "--bucket", "bucket", "--body", path), nil)
+	dir = filepath.Join(t.TempDir(), "a", "b")
+	runWithError(t, args("storage", "object", "download", "logs", "--bucket", "bucket", "--recursive", "-O", dir), nil)
+	runWithError(t, args("storage", "sync", "s3://bucket/logs", dir), nil)
+	assert.NoFileExists(t, filepath.Join(dir, "..", "..", "escape.txt"))
+}
+
+func TestSyncDiffers(t *testing.T) {
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := before.Add(time.Hour)
+	tests := []struct {
+		name     string
+		src, dst cmd.SyncEntry
+		differs  bool
+	}{
+		{name: "sizes differ", src: cmd.SyncEntry{Size: 1, ModTime: before}, dst: cmd.SyncEntry{Size: 2, ModTime: after}, differs: true},
+		{name: "destination newer", src: cmd.SyncEntry{Size: 1, ModTime: before, ETag: "a"}, dst: cmd.SyncEntry{Size: 1, ModTime: after, ETag: "b"}},
+		{name: "source newer, same content", src: cmd.SyncEntry{Size: 1, ModTime: after, ETag: "a"}, dst: cmd.SyncEntry{Size: 1, ModTime: before, ETag: "a"}},
+		{name: "source newer, content differs", src: cmd.SyncEntry{Size: 1, ModTime: after, ETag: "a"}, dst: cmd.SyncEntry{Size: 1, ModTime: before, ETag: "b"}, differs: true},
+		{name: "source newer, multipart ETag", src: cmd.SyncEntry{Size: 1, ModTime: after, ETag: "a-2"}, dst: cmd.SyncEntry{Size: 1, ModTime: before, ETag: "a-2"}, differs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.differs, cmd.SyncDiffers(tt.src, tt.dst))
+		})
+	}
+}
+
+func TestSyncMatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		rel              string
+		match            bool
+	}{
+		{name: "no pattern", rel: "css/site.css", match: true},
+		{name: "name included", include: []string{"*.css"}, rel: "css/site.css", match: true},
+		{name: "name not included", include: []string{"*.css"}, rel: "index.html"},
+		{name: "name excluded", exclude: []string{"*.log"}, rel: "logs/debug.log"},
+		{name: "path excluded", exclude: []string{"tmp/*"}, rel: "tmp/cache"},
+		{name: "path pattern not matching names", exclude: []string{"tmp/*"}, rel: "www/tmp/cache", match: true},
+		{name: "exclude wins over include", include: []string{"*.css"}, exclude: []string{"vendor/*"}, rel: "vendor/lib.css"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := cmd.SyncMatch(tt.include, tt.exclude, tt.rel)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, match)
+		})
+	}
+	_, err := cmd.SyncMatch([]string{"["}, nil, "file")
+	require.Error(t, err)
+}
@@ -0,0 +1,455 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/outscale/octl/pkg/alias"
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/outscale/octl/pkg/style"
+	"github.com/outscale/osc-sdk-go/v3/pkg/oks"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// resolveFunc builds the graph of a resource and of all its dependencies.
+type resolveFunc func(cmd *cobra.Command, id string) (*graph.Graph, error)
+
+// checkFunc checks that a graph can be torn down.
+type checkFunc func(cmd *cobra.Command, g *graph.Graph) error
+
+// dependencyEntity is an entity having dependencies and teardown commands.
+type dependencyEntity struct {
+	// service is the service of the entity, iaas or kube.
+	service string
+	// name is the name of the entity, in help messages.
+	name string
+	// arg is the argument identifying the resource to tear down.
+	arg     string
+	resolve resolveFunc
+	// check is optional.
+	check checkFunc
+}
+
+// dependencyCommands builds the dependencies and teardown commands of an entity.
+func dependencyCommands(e dependencyEntity) (deps, teardown *cobra.Command) {
+	deps = &cobra.Command{
+		Use:     "dependencies " + e.arg,
+		Aliases: []string{"deps"},
+		Short:   "Shows all dependencies of a " + e.name,
+		Long: "Shows all dependencies of a " + e.name + ", as a tree.\n\n" +
+			"With -o json or -o yaml, the tree is exported with the type, ID, name, links and children of each resource. " +
+			"With -o dot or -o mermaid, it is exported as a Graphviz or Mermaid diagram, links being drawn as dashed edges.",
+		Run: exportDependencies(e.resolve),
+	}
+	teardown = &cobra.Command{
+		Use:   "teardown " + e.arg,
+		Short: "Tears down a " + e.name + " and its subresources",
+		Run:   teardownRun(e),
+	}
+	teardown.Flags().Duration("timeout", 10*time.Minute, "Timeout for a single resource deletion")
+	teardown.Flags().Bool("dry-run", false, "display the deletion plan, without deleting anything - use -o json or -o yaml to save it")
+	teardown.Flags().String("from-plan", "", "execute a deletion plan, written by --dry-run")
+	teardown.Flags().Int(alias.ParallelFlag, 8, "maximum number of resources deleted concurrently, within a phase")
+	teardown.Flags().Bool("resume", false, "resume an interrupted teardown, from its journal")
+	teardown.MarkFlagsMutuallyExclusive("dry-run", "from-plan", "resume")
+	status := &cobra.Command{
+		Use:   "status [" + e.arg + "]",
+		Short: "Shows the resources left by interrupted teardowns",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   teardownStatus(e.service),
+	}
+	teardown.AddCommand(status)
+	return deps, teardown
+}
+
+func teardownRun(e dependencyEntity) func(cmd *cobra.Command, args []string) {
+	fromGraph := alias.Confirm(config.ActionDelete, displayDependencies(e.resolve, e.check), teardownResource(e))
+	fromPlan := alias.Confirm(config.ActionDelete, displayPlan, teardownFromPlan(e.service))
+	fromJournal := alias.Confirm(config.ActionDelete, displayJournal(e.service), resumeTeardown(e.service))
+	return func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		path, _ := cmd.Flags().GetString("from-plan")
+		resume, _ := cmd.Flags().GetBool("resume")
+		switch {
+		case dryRun:
+			writePlan(cmd, args, e)
+		case path != "":
+			fromPlan(cmd, args)
+		case resume:
+			fromJournal(cmd, args)
+		default:
+			fromGraph(cmd, args)
+		}
+	}
+}
+
+func resolveGraph(cmd *cobra.Command, args []string, resolve resolveFunc) (*graph.Graph, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("not enough arguments for %s", cmd.Name())
+	}
+	cancel := spinner.Run(cmd.Context(), "Building dependency tree...")
+	defer cancel()
+	return resolve(cmd, args[0])
+}
+
+func displayDependencies(resolve resolveFunc, check checkFunc) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		g, err := resolveGraph(cmd, args, resolve)
+		if err == nil {
+			err = g.Write(os.Stdout)
+		}
+		if err == nil && check != nil {
+			err = check(cmd, g)
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+}
+
+// exportDependencies displays the dependencies of a resource, as a tree or in the format set by --output.
+func exportDependencies(resolve resolveFunc) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("output")
+		if out == "" {
+			displayDependencies(resolve, nil)(cmd, args)
+			return
+		}
+		g, err := resolveGraph(cmd, args, resolve)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+		switch strings.ToLower(out) {
+		case "dot":
+			err = g.WriteDOT(os.Stdout)
+		case "mermaid":
+			err = g.WriteMermaid(os.Stdout)
+		default:
+			var fmter format.Interface
+			fmter, _, err = output.NewFromFlags(cmd.Flags(), "", "", nil, false, false)
+			if err == nil {
+				var v any = g.Trees()
+				if len(g.Roots()) == 1 {
+					v = g.Trees()[0]
+				}
+				err = fmter.Format(cmd.Context(), os.Stdout, v)
+			}
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+}
+
+// buildPlan builds the deletion plan of a resource.
+func buildPlan(cmd *cobra.Command, args []string, e dependencyEntity) (*graph.Plan, error) {
+	g, err := resolveGraph(cmd, args, e.resolve)
+	if err != nil {
+		return nil, err
+	}
+	if e.check != nil {
+		if err := e.check(cmd, g); err != nil {
+			return nil, err
+		}
+	}
+	return g.Plan(e.service)
+}
+
+func writePlan(cmd *cobra.Command, args []string, e dependencyEntity) {
+	plan, err := buildPlan(cmd, args, e)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	fmter, _, err := output.NewFromFlags(cmd.Flags(), "yaml", "", nil, false, false)
+	if err == nil {
+		err = fmter.Format(cmd.Context(), os.Stdout, plan)
+	}
+	if err != nil {
+		messages.ExitErr(err)
+	}
+}
+
+func loadPlan(cmd *cobra.Command) *graph.Plan {
+	path, _ := cmd.Flags().GetString("from-plan")
+	plan, err := graph.LoadPlan(path)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	return plan
+}
+
+func displayPlan(cmd *cobra.Command, args []string) {
+	writePhases(loadPlan(cmd))
+}
+
+func writePhases(plan *graph.Plan) {
+	for i, phase := range plan.Phases() {
+		if len(phase) == 0 {
+			continue
+		}
+		fmt.Printf("Phase %d\n", i+1)
+		for _, s := range phase {
+			calls := lo.Map(s.Calls, func(c graph.Call, _ int) string { return c.Call })
+			fmt.Println("  " + s.String() + " " + style.Faint.Render(strings.Join(calls, ", ")))
+		}
+	}
+}
+
+func teardownResource(e dependencyEntity) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		debug.Println(cmd.Name() + " called")
+		plan, err := buildPlan(cmd, args, e)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+		executePlan(cmd, plan, nil)
+	}
+}
+
+func teardownFromPlan(service string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		debug.Println(cmd.Name() + " called")
+		plan := loadPlan(cmd)
+		if plan.Service != service {
+			messages.ExitErr(fmt.Errorf("the plan is a %s plan, not a %s one", plan.Service, service))
+		}
+		executePlan(cmd, plan, nil)
+	}
+}
+
+// serviceClient returns the client of a service, to make the calls of a plan.
+func serviceClient(cmd *cobra.Command, service string) (any, error) {
+	switch service {
+	case "kube":
+		return oks.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	default:
+		return osc.NewClient(loadProfile(cmd), sdkOptions(cmd)...)
+	}
+}
+
+// teardownResult is the result of a deletion which has not succeeded.
+type teardownResult struct {
+	Resource string
+	State    string
+	Error    string
+}
+
+var teardownColumns = config.Columns{
+	{Title: "Resource", Content: ".Resource"},
+	{Title: "State", Content: ".State"},
+	{Title: "Error", Content: ".Error"},
+}
+
+// executePlan deletes all resources of a plan, phase by phase, the resources of a phase being deleted concurrently.
+// If a deletion fails, the current phase is completed, later phases are skipped, and a report is displayed.
+// Progress is recorded in a journal, j being the journal of a resumed teardown, or nil to create a new one.
+// The journal is removed once all resources are deleted.
+func executePlan(cmd *cobra.Command, plan *graph.Plan, j *graph.Journal) {
+	cl, err := serviceClient(cmd, plan.Service)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	if j == nil {
+		j, err = newJournal(plan)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+	tmout, _ := cmd.Flags().GetDuration("timeout")
+	parallel, _ := cmd.Flags().GetInt(alias.ParallelFlag)
+	parallel = max(parallel, 1)
+
+	remaining := &graph.Plan{Service: plan.Service, Resource: plan.Resource, Steps: j.Remaining()}
+	var failed []teardownResult
+	for i, phase := range remaining.Phases() {
+		if len(phase) == 0 {
+			continue
+		}
+		if len(failed) > 0 {
+			for _, s := range phase {
+				failed = append(failed, teardownResult{Resource: s.String(), State: "skipped"})
+			}
+			continue
+		}
+		debug.Println("phase", i+1, "-", len(phase), "resource(s)")
+		failed = append(failed, deletePhase(cmd.Context(), cl, j, phase, parallel, tmout)...)
+	}
+	if len(failed) == 0 {
+		if err := j.Remove(); err != nil {
+			messages.ExitErr(err)
+		}
+		return
+	}
+	tbl := format.Tabular{Columns: teardownColumns, Formatter: format.TableFormatter{}}
+	if err := tbl.Format(cmd.Context(), os.Stderr, failed); err != nil {
+		messages.ExitErr(err)
+	}
+	messages.Exit(1, "%d of %d resource(s) not deleted - run teardown %s --resume to continue", len(failed), len(plan.Steps), plan.Resource.ID)
+}
+
+// deletePhase deletes all resources of a phase, with at most parallel concurrent deletions.
+func deletePhase(ctx context.Context, cl any, j *graph.Journal, phase []graph.Step, parallel int, tmout time.Duration) []teardownResult {
+	progress := spinner.NewProgress()
+	tasks := lo.Map(phase, func(s graph.Step, _ int) *spinner.Task { return progress.Add(s.String()) })
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallel)
+		results = make([]*teardownResult, len(phase))
+	)
+	for i, s := range phase {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			results[i] = deleteStep(ctx, cl, j, s, tasks[i], tmout)
+		})
+	}
+	wg.Wait()
+	progress.Stop()
+	return lo.FilterMap(results, func(r *teardownResult, _ int) (teardownResult, bool) {
+		return lo.FromPtr(r), r != nil
+	})
+}
+
+// runCalls makes the calls of a step, starting from the first call not recorded in the journal.
+// A retry after a partial failure thus does not repeat calls which have already succeeded, e.g. an unlink.
+func runCalls(ctx context.Context, cl any, j *graph.Journal, s graph.Step) error {
+	called, _, _ := j.Progress(s)
+	for _, c := range s.Calls[called:] {
+		_, err := runner.CallJSON(ctx, cl, c.Call, c.Params)
+		if err := ignoreDeleteError(c.Call, err); err != nil {
+			return fmt.Errorf("%s: %w", c.Call, err)
+		}
+		if err := j.Record(s, graph.Called, c.Call, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goneCall is the read of a deleted resource, to wait until it is gone.
+type goneCall struct {
+	graph.Call
+	// content is the list of the response, and id the attribute identifying the resource in the list.
+	content, id string
+}
+
+// goneStates are the states of deleted resources still listed for a while.
+var goneStates = []any{"deleted", "terminated"}
+
+// gone checks if a response no longer lists the resource id, or only lists it in a deleted state.
+func (c goneCall) gone(resp map[string]any, id string) bool {
+	items, _ := resp[c.content].([]any)
+	return !slices.ContainsFunc(items, func(item any) bool {
+		res, _ := item.(map[string]any)
+		return res[c.id] == id && !lo.Contains(goneStates, res["State"])
+	})
+}
+
+// goneCalls are the reads of deleted resources, by type. Resources of other types are gone once deleted.
+var goneCalls = lo.Assign(iaasGoneCalls, kubeGoneCalls)
+
+// waitGone reads the resource of a deleted step until it is gone, the deletion of some resources being asynchronous.
+func waitGone(ctx context.Context, cl any, s graph.Step, task *spinner.Task) error {
+	read, found := goneCalls[s.Type]
+	// steps without calls keep resources which are still used
+	if !found || len(s.Calls) == 0 {
+		return nil
+	}
+	c := read(s.ID)
+	t := time.NewTicker(5 * time.Second)
+	defer t.Stop()
+	for {
+		resp, err := runner.CallJSON(ctx, cl, c.Call.Call, c.Params)
+		switch {
+		case osc.IsNotFound(err):
+			return nil
+		case err == nil && c.gone(resp, s.ID):
+			return nil
+		case err != nil:
+			err = fmt.Errorf("%s: %w", c.Call.Call, err)
+		default:
+			err = errors.New("still present")
+		}
+		task.Set(spinner.Running, "waiting until gone - "+err.Error())
+		select {
+		case <-ctx.Done():
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// deleteStep deletes a resource, retrying until the timeout is reached, and waits until it is gone.
+// A result is returned if the deletion fails.
+func deleteStep(ctx context.Context, cl any, j *graph.Journal, s graph.Step, task *spinner.Task, tmout time.Duration) *teardownResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, tmout)
+	defer cancel()
+	task.Set(spinner.Running, "")
+	// a resumed step may already be deleted, and only wait until it is gone
+	_, deleted, _ := j.Progress(s)
+	var err error
+	if !deleted {
+		err = runCalls(ctx, cl, j, s)
+	}
+	if err != nil {
+		t := time.NewTicker(20 * time.Second)
+		defer t.Stop()
+	LOOPRETRY:
+		for {
+			task.Set(spinner.Running, "retrying - "+err.Error())
+			select {
+			case <-ctx.Done():
+				break LOOPRETRY
+			case <-t.C:
+				err = runCalls(ctx, cl, j, s)
+				if err == nil {
+					break LOOPRETRY
+				}
+			}
+		}
+	}
+	if err == nil && !deleted {
+		err = j.Record(s, graph.Deleted, "", nil)
+	}
+	if err == nil {
+		err = waitGone(ctx, cl, s, task)
+	}
+	if err == nil {
+		err = j.Record(s, graph.Gone, "", nil)
+	}
+	if err != nil {
+		res := &teardownResult{Resource: s.String(), State: "failed", Error: err.Error()}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			res.State = "timed out"
+			res.Error = fmt.Sprintf("not deleted in %s: %v", tmout, err)
+		}
+		if err := j.Record(s, graph.Failed, "", errors.New(res.Error)); err != nil {
+			debug.Println("unable to record failure:", err)
+		}
+		task.Set(spinner.Failed, res.State)
+		return res
+	}
+	task.Set(spinner.Succeeded, "deleted in "+time.Since(start).Round(time.Millisecond).String())
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+// journalDir returns the directory of the teardown journals of a service.
+func journalDir(service string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("config dir: %w", err)
+	}
+	return filepath.Join(dir, "octl", "teardown", service), nil
+}
+
+// newJournal creates the journal of a teardown.
+// An existing journal for the same resource means that a previous teardown was interrupted, and needs to be resumed first.
+func newJournal(plan *graph.Plan) (*graph.Journal, error) {
+	dir, err := journalDir(plan.Service)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ReplaceAll(string(plan.Resource.Type), " ", "-") + "-" + plan.Resource.ID + ".json"
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("a teardown of %s was interrupted - use --resume to continue it, or remove %s", plan.Resource, path)
+	}
+	return graph.NewJournal(path, plan)
+}
+
+// listJournals loads all teardown journals of a service.
+func listJournals(service string) ([]*graph.Journal, error) {
+	dir, err := journalDir(service)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list journals: %w", err)
+	}
+	var js []*graph.Journal
+	for _, path := range paths {
+		j, err := graph.LoadJournal(path)
+		if err != nil {
+			debug.Println("skipping journal:", err)
+			continue
+		}
+		js = append(js, j)
+	}
+	return js, nil
+}
+
+// findJournal finds the journal of a resource, by ID or by name.
+func findJournal(service, id string) (*graph.Journal, error) {
+	js, err := listJournals(service)
+	if err != nil {
+		return nil, err
+	}
+	j, found := lo.Find(js, func(j *graph.Journal) bool {
+		return j.Plan.Resource.ID == id || (j.Plan.Resource.Name != "" && j.Plan.Resource.Name == id)
+	})
+	if !found {
+		return nil, fmt.Errorf("no interrupted teardown found for %s", id)
+	}
+	return j, nil
+}
+
+func loadJournal(cmd *cobra.Command, args []string, service string) *graph.Journal {
+	if len(args) == 0 {
+		messages.ExitErr(fmt.Errorf("not enough arguments for %s", cmd.Name()))
+	}
+	j, err := findJournal(service, args[0])
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	return j
+}
+
+func displayJournal(service string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		j := loadJournal(cmd, args, service)
+		writePhases(&graph.Plan{Service: j.Plan.Service, Resource: j.Plan.Resource, Steps: j.Remaining()})
+	}
+}
+
+func resumeTeardown(service string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		debug.Println(cmd.Name() + " called")
+		j := loadJournal(cmd, args, service)
+		executePlan(cmd, j.Plan, j)
+	}
+}
+
+// teardownStep is the state of a step of an interrupted teardown.
+type teardownStep struct {
+	Teardown string
+	Resource string
+	Phase    int
+	State    string
+	Calls    string
+	Error    string
+}
+
+var teardownStepColumns = config.Columns{
+	{Title: "Teardown", Content: ".Teardown"},
+	{Title: "Resource", Content: ".Resource"},
+	{Title: "Phase", Content: ".Phase"},
+	{Title: "State", Content: ".State"},
+	{Title: "Calls", Content: ".Calls"},
+	{Title: "Error", Content: ".Error"},
+}
+
+func teardownStatus(service string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		debug.Println(cmd.Name() + " called")
+		js, err := listJournals(service)
+		if err != nil {
+			messages.ExitErr(err)
+		}
+		if len(args) > 0 {
+			js = lo.Filter(js, func(j *graph.Journal, _ int) bool {
+				return j.Plan.Resource.ID == args[0] || j.Plan.Resource.Name == args[0]
+			})
+		}
+		if len(js) == 0 {
+			messages.Info("No interrupted teardown.")
+			return
+		}
+		var steps []teardownStep
+		for _, j := range js {
+			for _, s := range j.Remaining() {
+				called, deleted, lastErr := j.Progress(s)
+				state := "pending"
+				switch {
+				case lastErr != "":
+					state = "failed"
+				case deleted:
+					state = "waiting until gone"
+				case called > 0:
+					state = "in progress"
+				}
+				steps = append(steps, teardownStep{
+					Teardown: j.Plan.Resource.String(),
+					Resource: s.String(),
+					Phase:    s.Phase,
+					State:    state,
+					Calls:    fmt.Sprintf("%d/%d", called, len(s.Calls)),
+					Error:    lastErr,
+				})
+			}
+		}
+		fmter, _, err := output.NewFromFlags(cmd.Flags(), "table", "", teardownStepColumns, false, false)
+		if err == nil {
+			err = fmter.Format(cmd.Context(), os.Stdout, lo.ToAnySlice(steps))
+		}
+		if err != nil {
+			messages.ExitErr(err)
+		}
+	}
+}
@@ -19,13 +19,39 @@ func main() {
 	if err != nil {
 		messages.ExitErr(err)
 	}
-	os.Args, err = runner.TemplateArgs(os.Args)
-	if err != nil {
+	ctx := context.Background()
+	// JSON Lines: each line is used as input of a separate call, failed lines being reported without stopping
+	if lines, ok := runner.StdinLines(); ok {
+		args := os.Args
+		var failed int
+		for i, line := range lines {
+			runner.InjectStdin(line)
+			err := messages.CatchExit(func() error { return execute(ctx, args) })
+			if err != nil {
+				messages.Err("line %d: %v", i+1, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			messages.Exit(1, "%d of %d line(s) failed", failed, len(lines))
+		}
+		return
+	}
+	if err := execute(ctx, os.Args); err != nil {
 		messages.ExitErr(err)
 	}
-	ctx := context.Background()
-	err = cmd.Root().ExecuteContext(ctx)
+}
+
+func execute(ctx context.Context, args []string) error {
+	var err error
+	os.Args, err = runner.TemplateArgs(args)
 	if err != nil {
-		messages.ExitErr(err)
+		return err
+	}
+	// flags are reset even if the call fails, for the next line to be parsed from scratch
+	if c, _, err := cmd.Root().Find(os.Args[1:]); err == nil {
+		defer runner.ResetFlags(c.Flags())
 	}
+	_, err = cmd.Root().ExecuteContextC(ctx)
+	return err
 }
@@ -0,0 +1,129 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package alias
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+// ParallelFlag is the flag setting the number of concurrent runs of an iterative alias.
+const ParallelFlag = "parallel"
+
+const (
+	stateSucceeded = "succeeded"
+	stateFailed    = "failed"
+)
+
+type parallelResult struct {
+	ID    string
+	State string
+	Error string
+}
+
+var parallelColumns = config.Columns{
+	{Title: "ID", Content: ".ID"},
+	{Title: "State", Content: ".State"},
+	{Title: "Error", Content: ".Error"},
+}
+
+// runParallel runs an iterative alias once per ID, with at most parallel concurrent runs.
+// Each run is a separate octl process, so that a failure does not stop the other runs.
+func runParallel(cmd *cobra.Command, build buildFunc, args []string, parallel int) {
+	self, err := os.Executable()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	type run struct {
+		ids   []string
+		nargs []string
+	}
+	var runs []run
+	for len(args) > 0 {
+		nargs, consumed := build(cmd, args)
+		if consumed <= 0 || consumed > len(args) {
+			consumed = len(args)
+		}
+		runs = append(runs, run{ids: args[:consumed], nargs: nargs})
+		args = args[consumed:]
+	}
+	stdin, _ := runner.Stdin()
+
+	var (
+		wg     sync.WaitGroup
+		outMu  sync.Mutex
+		sem    = make(chan struct{}, parallel)
+		result = make([]parallelResult, len(runs))
+	)
+	for i, r := range runs {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			debug.Println("running", r.nargs)
+			c := exec.CommandContext(cmd.Context(), self, r.nargs[1:]...) //nolint:gosec
+			if len(stdin) > 0 {
+				c.Stdin = bytes.NewReader(stdin)
+			}
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			c.Stdout = stdout
+			c.Stderr = stderr
+			err := c.Run()
+
+			res := parallelResult{ID: strings.Join(r.ids, " "), State: stateSucceeded}
+			if err != nil {
+				res.State = stateFailed
+				res.Error = exitMessage(stderr.String())
+				if res.Error == "" {
+					res.Error = err.Error()
+				}
+			}
+			result[i] = res
+			// outputs of a run are written together, the whole error output being kept
+			outMu.Lock()
+			_, _ = stdout.WriteTo(os.Stdout)
+			_, _ = stderr.WriteTo(os.Stderr)
+			outMu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	tbl := format.Tabular{Columns: parallelColumns, Formatter: format.TableFormatter{}}
+	err = tbl.Format(cmd.Context(), os.Stderr, result)
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	failed := 0
+	for _, res := range result {
+		if res.State == stateFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		messages.Exit(1, "%d of %d run(s) failed", failed, len(result))
+	}
+}
+
+// exitMessage returns the error messages of an error output, without their prefix, other lines being ignored.
+func exitMessage(s string) string {
+	var msgs []string
+	for line := range strings.Lines(s) {
+		msg, found := strings.CutPrefix(strings.TrimSpace(line), "❌ ")
+		if found {
+			msgs = append(msgs, strings.TrimPrefix(msg, "an error occurred: "))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -20,9 +20,15 @@ import (
 var (
 	prompts = map[config.Action]string{
 		config.ActionDelete: "Are you sure you want to delete these resource(s) ?",
+		config.ActionTag:    "Are you sure you want to tag these resource(s) ?",
+		config.ActionUntag:  "Are you sure you want to untag these resource(s) ?",
+		config.ActionUpdate: "Are you sure you want to update these resource(s) ?",
 	}
 	success = map[config.Action]string{
 		config.ActionDelete: "The resource(s) have been deleted",
+		config.ActionTag:    "The resource(s) have been tagged",
+		config.ActionUntag:  "The resource(s) have been untagged",
+		config.ActionUpdate: "The resource(s) have been updated",
 	}
 )
 
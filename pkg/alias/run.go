@@ -97,16 +97,35 @@ func restoreFlags(fs *pflag.FlagSet, saved map[string][]string) {
 	})
 }
 
-func once(fn func(cmd *cobra.Command, args []string) int) func(cmd *cobra.Command, args []string) {
+// buildFunc builds the command line of an alias, and returns the number of args consumed.
+type buildFunc func(cmd *cobra.Command, args []string) ([]string, int)
+
+func execute(cmd *cobra.Command, nargs []string) {
+	saved := saveFlags(cmd.Flags())
+	os.Args = nargs
+	err := cmd.Execute()
+	if err != nil {
+		messages.ExitErr(err)
+	}
+	restoreFlags(cmd.Flags(), saved)
+}
+
+func once(build buildFunc) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
-		_ = fn(cmd, args)
+		nargs, _ := build(cmd, args)
+		execute(cmd, nargs)
 	}
 }
 
-func iterate(fn func(cmd *cobra.Command, args []string) int) func(cmd *cobra.Command, args []string) {
+func iterate(build buildFunc) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if parallel, _ := cmd.Flags().GetInt(ParallelFlag); parallel > 0 {
+			runParallel(cmd, build, args, parallel)
+			return
+		}
 		for {
-			consumed := fn(cmd, args)
+			nargs, consumed := build(cmd, args)
+			execute(cmd, nargs)
 			debug.Println("consumed", consumed, "len", len(args))
 			if consumed <= 0 || len(args) == consumed {
 				break
@@ -116,14 +135,23 @@ func iterate(fn func(cmd *cobra.Command, args []string) int) func(cmd *cobra.Com
 	}
 }
 
+// IsIterative returns true if the alias is run once per argument.
+func IsIterative(a config.Alias) bool {
+	return !slices.Contains(a.Command, "|") && !slices.Contains(a.Command, "%*") && countPlaceholders(a.Command) == 1
+}
+
+func countPlaceholders(command []string) int {
+	return lo.CountBy(command, func(arg string) bool { return strings.HasPrefix(arg, "%") })
+}
+
 func runFunc(rootPath string, command []string, flags config.FlagSet, skipUserFlags bool) func(cmd *cobra.Command, args []string) {
-	exec := func(cmd *cobra.Command, args []string) int {
+	build := func(cmd *cobra.Command, args []string) ([]string, int) {
 		nargs := make([]string, 2, len(command)+2)
 		nargs[0] = "octl"
 		nargs[1] = rootPath
 		var userArgs []string
 		cmd.Flags().VisitAll(func(f *pflag.Flag) {
-			if f.Changed {
+			if f.Changed && f.Name != ParallelFlag {
 				newFlag := f.Name
 				nf, found := flags.Get(newFlag)
 				switch {
@@ -164,7 +192,7 @@ func runFunc(rootPath string, command []string, flags config.FlagSet, skipUserFl
 			if arg == "%*" {
 				if len(args) == 0 {
 					_ = cmd.Usage()
-					os.Exit(1)
+					messages.Quit(1)
 				}
 				nargs = append(nargs, strings.Join(args, ","))
 				consumed = len(args) - 1
@@ -177,7 +205,7 @@ func runFunc(rootPath string, command []string, flags config.FlagSet, skipUserFl
 			}
 			if idx >= len(args) {
 				_ = cmd.Usage()
-				os.Exit(1)
+				messages.Quit(1)
 			}
 			nargs = append(nargs, args[idx])
 			consumed = max(consumed, idx)
@@ -190,19 +218,11 @@ func runFunc(rootPath string, command []string, flags config.FlagSet, skipUserFl
 		messages.Info("Resolving alias to %v", nargs)
 		// no need to check for an update a second time
 		nargs = append(nargs, "--no-upgrade")
-
-		saved := saveFlags(cmd.Flags())
-		os.Args = nargs
-		err = cmd.Execute()
-		if err != nil {
-			messages.ExitErr(err)
-		}
-		restoreFlags(cmd.Flags(), saved)
-		return consumed + 1
+		return nargs, consumed + 1
 	}
 
-	if lo.CountBy(command, func(arg string) bool { return strings.HasPrefix(arg, "%") }) == 1 {
-		return iterate(exec)
+	if countPlaceholders(command) == 1 {
+		return iterate(build)
 	}
-	return once(exec)
+	return once(build)
 }
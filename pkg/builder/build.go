@@ -16,12 +16,15 @@ import (
 	"github.com/outscale/octl/pkg/descriptions"
 	"github.com/outscale/octl/pkg/flags"
 	"github.com/outscale/octl/pkg/markdown"
+	"github.com/outscale/octl/pkg/runner"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
 var md = markdown.NewRenderer()
 
+const planUsage = "display the changes the call would make to the current state of the resource, without making the call"
+
 type Builder[T any] struct {
 	provider string
 	cfg      config.Config
@@ -78,6 +81,12 @@ func (b *Builder[T]) Build(rootCmd, apiCmd *cobra.Command) {
 			Run:     alias.RunFunc(rootPath, a),
 		}
 		serviceCmd.AddCommand(cmd)
+		if alias.IsIterative(a) {
+			cmd.Flags().Int(alias.ParallelFlag, 0, "number of concurrent calls, one per ID - failures are reported once all IDs have been processed")
+		}
+		if _, ok := runner.PlanCall(b.cfg, reflect.TypeFor[*T](), a.AliasTo); ok {
+			cmd.Flags().Bool(runner.PlanFlag, false, planUsage)
+		}
 		if apiCmd == nil {
 			continue
 		}
@@ -187,6 +196,9 @@ func (b *Builder[T]) buildAPIFor(
 			arg := m.Type.In(j)
 			b.BuildArgsAndFlags(cmd, arg)
 		}
+		if _, ok := runner.PlanCall(b.cfg, ct, m.Name); ok {
+			cmd.Flags().Bool(runner.PlanFlag, false, planUsage)
+		}
 
 		apiCmd.AddCommand(cmd)
 	}
@@ -107,6 +107,9 @@ type Action string
 
 const (
 	ActionDelete Action = "delete"
+	ActionTag    Action = "tag"
+	ActionUntag  Action = "untag"
+	ActionUpdate Action = "update"
 )
 
 type FlagSet []Flag
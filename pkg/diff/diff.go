@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package diff computes field-level differences between the current state of a resource and a requested state, or
+// between two snapshots of resources.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/outscale/octl/pkg/style"
+	"github.com/samber/lo"
+)
+
+type Kind string
+
+const (
+	Unchanged Kind = " "
+	Added     Kind = "+"
+	Removed   Kind = "-"
+	Updated   Kind = "~"
+)
+
+// Change is the change of a single field.
+type Change struct {
+	Path      string
+	Kind      Kind
+	Current   any
+	Requested any
+}
+
+// Compare compares all fields set in requested with the same fields of current.
+// Fields of current absent from requested are ignored, except for items of lists of objects.
+// Values are compared using their JSON representation.
+func Compare(current, requested any) ([]Change, error) {
+	cur, err := normalize(current)
+	if err != nil {
+		return nil, err
+	}
+	req, err := normalize(requested)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	compare("", cur, cur != nil, req, false, &changes)
+	return changes, nil
+}
+
+// CompareAll compares all fields of previous and next, fields absent from next being reported as removed.
+func CompareAll(previous, next any) ([]Change, error) {
+	prev, err := normalize(previous)
+	if err != nil {
+		return nil, err
+	}
+	nxt, err := normalize(next)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	compare("", prev, prev != nil, nxt, true, &changes)
+	return changes, nil
+}
+
+func normalize(v any) (any, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("diff: %w", err)
+	}
+	var res any
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return nil, fmt.Errorf("diff: %w", err)
+	}
+	return res, nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// compare compares req with cur, all being true if fields of cur absent from req are reported as removed.
+func compare(path string, cur any, found bool, req any, all bool, changes *[]Change) {
+	switch req := req.(type) {
+	case map[string]any:
+		curMap, _ := cur.(map[string]any)
+		keys := lo.Keys(req)
+		if all {
+			keys = lo.Union(keys, lo.Keys(curMap))
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			v, ok := curMap[k]
+			r, inReq := req[k]
+			if !inReq {
+				*changes = append(*changes, Change{Path: join(path, k), Kind: Removed, Current: v})
+				continue
+			}
+			compare(join(path, k), v, ok, r, all, changes)
+		}
+		return
+	case []any:
+		curList, _ := cur.([]any)
+		if slices.ContainsFunc(req, isObject) || (all && slices.ContainsFunc(curList, isObject)) {
+			for i := range max(len(req), len(curList)) {
+				p := join(path, strconv.Itoa(i))
+				switch {
+				case i >= len(req):
+					*changes = append(*changes, Change{Path: p, Kind: Removed, Current: curList[i]})
+				case i >= len(curList):
+					compare(p, nil, false, req[i], all, changes)
+				default:
+					compare(p, curList[i], true, req[i], all, changes)
+				}
+			}
+			return
+		}
+	}
+	c := Change{Path: path, Current: cur, Requested: req}
+	switch {
+	case !found:
+		c.Kind = Added
+	case reflect.DeepEqual(cur, req):
+		c.Kind = Unchanged
+	default:
+		c.Kind = Updated
+	}
+	*changes = append(*changes, c)
+}
+
+func isObject(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+// HasChanges returns true if at least one field changes.
+func HasChanges(changes []Change) bool {
+	return slices.ContainsFunc(changes, func(c Change) bool { return c.Kind != Unchanged })
+}
+
+// Write writes all changes, one per line.
+func Write(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		var line string
+		switch c.Kind {
+		case Added:
+			line = style.Renderf(style.Green, "+ %s: %s", c.Path, value(c.Requested))
+		case Removed:
+			line = style.Renderf(style.Red, "- %s: %s", c.Path, value(c.Current))
+		case Updated:
+			line = style.Renderf(style.Yellow, "~ %s: %s => %s", c.Path, value(c.Current), value(c.Requested))
+		default:
+			line = style.Renderf(style.Faint, "  %s: %s", c.Path, value(c.Requested))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func value(v any) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(buf)
+}
@@ -0,0 +1,93 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/outscale/octl/pkg/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	current := map[string]any{
+		"VmType":       "tinav5.c1r1p2",
+		"BsuOptimized": true,
+		"Tags":         []string{"a", "b"},
+		"Rules": []map[string]any{
+			{"ID": "a", "Status": "Enabled"},
+			{"ID": "b", "Status": "Enabled"},
+		},
+		"ImageId": "ami-foo",
+	}
+	requested := map[string]any{
+		"VmType":       "tinav6.c1r1p2",
+		"BsuOptimized": true,
+		"Tags":         []string{"a"},
+		"KeypairName":  "foo",
+		"Rules": []map[string]any{
+			{"ID": "a", "Status": "Disabled"},
+		},
+	}
+	changes, err := diff.Compare(current, requested)
+	require.NoError(t, err)
+	assert.Equal(t, []diff.Change{
+		{Path: "BsuOptimized", Kind: diff.Unchanged, Current: true, Requested: true},
+		{Path: "KeypairName", Kind: diff.Added, Requested: "foo"},
+		{Path: "Rules.0.ID", Kind: diff.Unchanged, Current: "a", Requested: "a"},
+		{Path: "Rules.0.Status", Kind: diff.Updated, Current: "Enabled", Requested: "Disabled"},
+		{Path: "Rules.1", Kind: diff.Removed, Current: map[string]any{"ID": "b", "Status": "Enabled"}},
+		{Path: "Tags", Kind: diff.Updated, Current: []any{"a", "b"}, Requested: []any{"a"}},
+		{Path: "VmType", Kind: diff.Updated, Current: "tinav5.c1r1p2", Requested: "tinav6.c1r1p2"},
+	}, changes)
+	assert.True(t, diff.HasChanges(changes))
+
+	changes, err = diff.Compare(nil, map[string]any{"IpRange": "10.0.0.0/16"})
+	require.NoError(t, err)
+	assert.Equal(t, []diff.Change{{Path: "IpRange", Kind: diff.Added, Requested: "10.0.0.0/16"}}, changes)
+
+	changes, err = diff.Compare(nil, map[string]any{"Rules": []any{map[string]any{"ID": "a"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []diff.Change{{Path: "Rules.0.ID", Kind: diff.Added, Requested: "a"}}, changes)
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	previous := []any{
+		map[string]any{"VmId": "i-foo", "VmType": "tinav5.c1r1p2", "Tags": []any{
+			map[string]any{"Key": "env", "Value": "staging"},
+			map[string]any{"Key": "owner", "Value": "team-a"},
+		}},
+		map[string]any{"VmId": "i-bar", "VmType": "tinav5.c1r1p2"},
+		map[string]any{"VmId": "i-baz", "VmType": "tinav5.c1r1p2", "KeypairName": "foo"},
+	}
+	next := []any{
+		map[string]any{"VmId": "i-foo", "VmType": "tinav6.c1r1p2", "Tags": []any{
+			map[string]any{"Key": "env", "Value": "production"},
+			map[string]any{"Key": "team", "Value": "a"},
+		}},
+		map[string]any{"VmId": "i-baz", "VmType": "tinav5.c1r1p2"},
+		map[string]any{"VmId": "i-qux", "VmType": "tinav5.c1r1p2"},
+	}
+	changes, err := diff.CompareSnapshots("VmId", previous, next)
+	require.NoError(t, err)
+	assert.Equal(t, []diff.ResourceChange{
+		{ID: "i-bar", Kind: diff.Removed},
+		{ID: "i-baz", Kind: diff.Updated, Changes: []diff.Change{
+			{Path: "KeypairName", Kind: diff.Removed, Current: "foo"},
+		}},
+		{ID: "i-foo", Kind: diff.Updated, Changes: []diff.Change{
+			{Path: "Tags.env", Kind: diff.Updated, Current: "staging", Requested: "production"},
+			{Path: "Tags.owner", Kind: diff.Removed, Current: "team-a"},
+			{Path: "Tags.team", Kind: diff.Added, Requested: "a"},
+			{Path: "VmType", Kind: diff.Updated, Current: "tinav5.c1r1p2", Requested: "tinav6.c1r1p2"},
+		}},
+		{ID: "i-qux", Kind: diff.Added},
+	}, changes)
+
+	_, err = diff.CompareSnapshots("NetId", previous, next)
+	require.Error(t, err)
+}
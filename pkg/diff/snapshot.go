@@ -0,0 +1,92 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package diff
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/samber/lo"
+)
+
+// ResourceChange is the change of a resource between two snapshots.
+type ResourceChange struct {
+	ID   string
+	Kind Kind
+	// Changes are the changed fields of an updated resource.
+	Changes []Change
+}
+
+// CompareSnapshots compares two snapshots of resources, resources being matched by their primary field.
+// Tags are compared by key, e.g. a change of the value of the env tag is reported on the Tags.env path.
+func CompareSnapshots(primary string, previous, next []any) ([]ResourceChange, error) {
+	prev, err := index(primary, previous)
+	if err != nil {
+		return nil, err
+	}
+	nxt, err := index(primary, next)
+	if err != nil {
+		return nil, err
+	}
+	ids := lo.Union(lo.Keys(prev), lo.Keys(nxt))
+	slices.Sort(ids)
+	var res []ResourceChange
+	for _, id := range ids {
+		p, inPrev := prev[id]
+		n, inNext := nxt[id]
+		switch {
+		case !inPrev:
+			res = append(res, ResourceChange{ID: id, Kind: Added})
+		case !inNext:
+			res = append(res, ResourceChange{ID: id, Kind: Removed})
+		default:
+			changes, err := CompareAll(p, n)
+			if err != nil {
+				return nil, err
+			}
+			changes = lo.Filter(changes, func(c Change, _ int) bool { return c.Kind != Unchanged })
+			if len(changes) > 0 {
+				res = append(res, ResourceChange{ID: id, Kind: Updated, Changes: changes})
+			}
+		}
+	}
+	return res, nil
+}
+
+// index indexes resources by their primary field, tags being converted to a key/value map.
+func index(primary string, resources []any) (map[string]map[string]any, error) {
+	idx := make(map[string]map[string]any, len(resources))
+	for _, r := range resources {
+		n, err := normalize(r)
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := n.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("diff: %v is not a resource", value(n))
+		}
+		id, ok := obj[primary].(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("diff: no %s found in %s", primary, value(n))
+		}
+		if tags, ok := obj["Tags"].([]any); ok {
+			obj["Tags"] = tagMap(tags)
+		}
+		idx[id] = obj
+	}
+	return idx, nil
+}
+
+func tagMap(tags []any) map[string]any {
+	m := make(map[string]any, len(tags))
+	for _, t := range tags {
+		t, _ := t.(map[string]any)
+		if k, ok := t["Key"].(string); ok {
+			m[k] = t["Value"]
+		}
+	}
+	return m
+}
@@ -0,0 +1,127 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package graph
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Link is a relationship between two resources, in addition to the tree structure (e.g. a route table linked to a
+// second subnet, or a NIC using a security group displayed below its VM).
+type Link struct {
+	From *Node
+	To   *Node
+}
+
+// Link records a relationship between two resources, only used when exporting the graph.
+// The deletion order is not changed.
+func (g *Graph) Link(from, to *Node) {
+	if from == nil || to == nil || from == to || slices.Contains(from.children, to) || slices.Contains(to.children, from) {
+		return
+	}
+	l := Link{From: from, To: to}
+	if lo.Contains(g.links, l) {
+		return
+	}
+	g.links = append(g.links, l)
+}
+
+// Links returns all relationships which are not part of the tree structure.
+func (g *Graph) Links() []Link {
+	return g.links
+}
+
+// TreeNode is the exported representation of a resource, with its children.
+type TreeNode struct {
+	Type     Type       `json:"Type"`
+	ID       string     `json:"Id"`
+	Name     string     `json:"Name,omitempty"`
+	Links    []Resource `json:"Links,omitempty"`
+	Children []TreeNode `json:"Children,omitempty"`
+}
+
+// Trees returns the exported representation of all trees of resources.
+func (g *Graph) Trees() []TreeNode {
+	return lo.Map(g.roots, func(n *Node, _ int) TreeNode { return g.treeNode(n) })
+}
+
+func (g *Graph) treeNode(n *Node) TreeNode {
+	t := TreeNode{Type: n.Type, ID: n.ID, Name: n.Name}
+	for _, l := range g.links {
+		if l.From == n {
+			t.Links = append(t.Links, Resource{Type: l.To.Type, ID: l.To.ID, Name: l.To.Name})
+		}
+	}
+	t.Children = lo.Map(n.children, func(c *Node, _ int) TreeNode { return g.treeNode(c) })
+	return t
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format.
+// Tree edges are solid, links are dashed.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	b := strings.Builder{}
+	b.WriteString("digraph {\n  rankdir=LR;\n  node [shape=box];\n")
+	for i, n := range g.nodes {
+		fmt.Fprintf(&b, "  n%d [label=%s];\n", i, strconv.Quote(label(n, "\n")))
+	}
+	ids := g.nodeIDs()
+	for _, n := range g.nodes {
+		for _, c := range n.children {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", ids[n], ids[c])
+		}
+	}
+	for _, l := range g.links {
+		fmt.Fprintf(&b, "  n%d -> n%d [style=dashed];\n", ids[l.From], ids[l.To])
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+// Tree edges are solid, links are dotted.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	b := strings.Builder{}
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.nodes {
+		lbl := strings.ReplaceAll(label(n, "<br/>"), `"`, "#quot;")
+		fmt.Fprintf(&b, "  n%d[\"%s\"]\n", i, lbl)
+	}
+	ids := g.nodeIDs()
+	for _, n := range g.nodes {
+		for _, c := range n.children {
+			fmt.Fprintf(&b, "  n%d --> n%d\n", ids[n], ids[c])
+		}
+	}
+	for _, l := range g.links {
+		fmt.Fprintf(&b, "  n%d -.-> n%d\n", ids[l.From], ids[l.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (g *Graph) nodeIDs() map[*Node]int {
+	ids := make(map[*Node]int, len(g.nodes))
+	for i, n := range g.nodes {
+		ids[n] = i
+	}
+	return ids
+}
+
+// label returns the label of a resource in a diagram, the type and the ID/name being separated by sep.
+func label(n *Node, sep string) string {
+	l := string(n.Type) + sep + n.ID
+	if n.Name != "" {
+		l += " (" + n.Name + ")"
+	}
+	return l
+}
@@ -0,0 +1,185 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package graph stores resources and the dependencies between them.
+//
+// Resources are displayed as a tree, and are deleted in phases, a resource being deleted only once all resources
+// depending on it have been deleted.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/outscale/octl/pkg/tree"
+	"github.com/samber/lo"
+)
+
+// Type is the type of a resource, e.g. net.
+type Type string
+
+// Node is a resource of the graph.
+type Node struct {
+	Type Type
+	ID   string
+	Name string
+
+	// Calls are the API calls deleting the resource. Resources without calls are kept on teardown.
+	Calls []Call
+
+	children []*Node
+}
+
+func (n *Node) String() string {
+	b := strings.Builder{}
+	_, _ = b.WriteString(string(n.Type))
+	_, _ = b.WriteString("/")
+	_, _ = b.WriteString(n.ID)
+	if n.Name != "" {
+		_, _ = b.WriteString(" (")
+		_, _ = b.WriteString(n.Name)
+		_, _ = b.WriteString(")")
+	}
+	return b.String()
+}
+
+func (n *Node) Children() []tree.Tree {
+	return lo.Map(n.children, func(c *Node, _ int) tree.Tree {
+		return tree.Tree(c)
+	})
+}
+
+type key struct {
+	typ Type
+	id  string
+}
+
+// Graph is a set of resources and of their dependencies.
+// A resource is only added once, and is displayed below the first resource it was added to.
+type Graph struct {
+	roots []*Node
+	nodes []*Node
+	index map[key]*Node
+	// deps stores, for each resource, the resources it depends on, which can only be deleted once it is deleted.
+	deps map[*Node][]*Node
+	// links are relationships which are not displayed in the tree.
+	links []Link
+}
+
+func New() *Graph {
+	return &Graph{
+		index: map[key]*Node{},
+		deps:  map[*Node][]*Node{},
+	}
+}
+
+// Find returns the resource having a type and an ID, or nil if the graph does not contain it.
+func (g *Graph) Find(typ Type, id string) *Node {
+	return g.index[key{typ: typ, id: id}]
+}
+
+// Nodes returns all resources, in the order they were added.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Roots returns the resources added without parent.
+func (g *Graph) Roots() []*Node {
+	return g.roots
+}
+
+// Add adds n below parent, n depending on parent (e.g. a subnet below its net). n is a root if parent is nil.
+// If the graph already contains the resource, only the dependency is added, and the existing resource is returned.
+func (g *Graph) Add(parent, n *Node) *Node {
+	n, _ = g.insert(parent, n)
+	if parent != nil {
+		g.DependsOn(n, parent)
+	}
+	return n
+}
+
+// Use adds n below user, user depending on n (e.g. a security group below a VM using it).
+// If the graph already contains the resource, the dependency and a link are added, and the existing resource is returned.
+func (g *Graph) Use(user, n *Node) *Node {
+	n, found := g.insert(user, n)
+	g.DependsOn(user, n)
+	if found {
+		g.Link(user, n)
+	}
+	return n
+}
+
+// DependsOn records that n depends on dep, dep being deleted after n.
+func (g *Graph) DependsOn(n, dep *Node) {
+	if n == dep || slices.Contains(g.deps[n], dep) {
+		return
+	}
+	g.deps[n] = append(g.deps[n], dep)
+}
+
+func (g *Graph) insert(parent, n *Node) (*Node, bool) {
+	k := key{typ: n.Type, id: n.ID}
+	if found, ok := g.index[k]; ok {
+		return found, true
+	}
+	g.index[k] = n
+	g.nodes = append(g.nodes, n)
+	if parent == nil {
+		g.roots = append(g.roots, n)
+	} else {
+		parent.children = append(parent.children, n)
+	}
+	return n, false
+}
+
+// Phases returns the deletion order of the resources, as a list of phases.
+// All resources of a phase can be deleted at the same time, once all resources of the previous phases are deleted.
+// Resources without calls are not returned, but are still ordered against other resources.
+func (g *Graph) Phases() ([][]*Node, error) {
+	dependents := map[*Node][]*Node{}
+	for _, n := range g.nodes {
+		for _, dep := range g.deps[n] {
+			dependents[dep] = append(dependents[dep], n)
+		}
+	}
+	deleted := map[*Node]bool{}
+	remaining := g.nodes
+	var phases [][]*Node
+	for len(remaining) > 0 {
+		var phase, next []*Node
+		for _, n := range remaining {
+			if lo.EveryBy(dependents[n], func(d *Node) bool { return deleted[d] }) {
+				phase = append(phase, n)
+			} else {
+				next = append(next, n)
+			}
+		}
+		if len(phase) == 0 {
+			return nil, fmt.Errorf("dependency cycle between %v", remaining)
+		}
+		for _, n := range phase {
+			deleted[n] = true
+		}
+		phase = lo.Filter(phase, func(n *Node, _ int) bool { return len(n.Calls) > 0 })
+		if len(phase) > 0 {
+			phases = append(phases, phase)
+		}
+		remaining = next
+	}
+	return phases, nil
+}
+
+// Write writes all trees of resources to w.
+func (g *Graph) Write(w io.Writer) error {
+	for _, r := range g.roots {
+		if err := tree.WriteTo(r, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/outscale/octl/pkg/graph"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func node(typ graph.Type, id string) *graph.Node {
+	return &graph.Node{Type: typ, ID: id, Calls: []graph.Call{{Call: "Delete", Params: map[string]any{"Id": id}}}}
+}
+
+func ids(phases [][]*graph.Node) [][]string {
+	return lo.Map(phases, func(phase []*graph.Node, _ int) []string {
+		return lo.Map(phase, func(n *graph.Node, _ int) string { return n.ID })
+	})
+}
+
+func TestGraph(t *testing.T) {
+	g := graph.New()
+	net := g.Add(nil, node("net", "vpc-foo"))
+	subnet := g.Add(net, node("subnet", "subnet-foo"))
+	vm := g.Add(subnet, node("vm", "i-foo"))
+	sg := g.Use(vm, node("security group", "sg-foo"))
+	g.DependsOn(sg, net)
+	g.Use(vm, &graph.Node{Type: "keypair", ID: "key"})
+
+	t.Run("Resources are only added once", func(t *testing.T) {
+		found := g.Add(net, node("security group", "sg-foo"))
+		assert.Same(t, sg, found)
+		assert.Len(t, g.Nodes(), 5)
+		assert.Same(t, sg, g.Find("security group", "sg-foo"))
+		assert.Nil(t, g.Find("security group", "sg-bar"))
+	})
+	t.Run("Resources are deleted after their dependents", func(t *testing.T) {
+		phases, err := g.Phases()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"i-foo"}, {"subnet-foo", "sg-foo"}, {"vpc-foo"}}, ids(phases))
+	})
+	t.Run("Resources are displayed below their parent", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, g.Write(buf))
+		assert.Equal(t, `net/vpc-foo
+└─ subnet/subnet-foo
+   └─ vm/i-foo
+      ├─ security group/sg-foo
+      └─ keypair/key
+`, buf.String())
+	})
+	t.Run("Plans can be saved and loaded", func(t *testing.T) {
+		plan, err := g.Plan("iaas")
+		require.NoError(t, err)
+		assert.Equal(t, "net/vpc-foo", plan.Resource.String())
+		require.Len(t, plan.Steps, 4)
+		assert.Equal(t, 2, plan.Steps[2].Phase)
+		buf, err := json.Marshal(plan)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "plan.json")
+		require.NoError(t, os.WriteFile(path, buf, 0o600))
+		loaded, err := graph.LoadPlan(path)
+		require.NoError(t, err)
+		assert.Equal(t, "iaas", loaded.Service)
+		phases := loaded.Phases()
+		require.Len(t, phases, 3)
+		assert.Equal(t, "sg-foo", phases[1][1].ID)
+		assert.Equal(t, map[string]any{"Id": "sg-foo"}, phases[1][1].Calls[0].Params)
+	})
+	t.Run("Plans without calls are rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plan.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("Service: iaas\nSteps:\n  - Phase: 1\n    Type: vm\n    Id: i-foo\n"), 0o600))
+		_, err := graph.LoadPlan(path)
+		require.Error(t, err)
+	})
+	t.Run("Journals record the progress of a plan", func(t *testing.T) {
+		plan, err := g.Plan("iaas")
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "teardown", "net-vpc-foo.json")
+		j, err := graph.NewJournal(path, plan)
+		require.NoError(t, err)
+		vm := plan.Steps[0]
+		vm.Calls = append(vm.Calls, graph.Call{Call: "Unlink"})
+		require.NoError(t, j.Record(vm, graph.Called, "Delete", nil))
+		require.NoError(t, j.Record(plan.Steps[1], graph.Called, "Delete", nil))
+		require.NoError(t, j.Record(plan.Steps[1], graph.Deleted, "", nil))
+		loaded, err := graph.LoadJournal(path)
+		require.NoError(t, err)
+		called, deleted, _ := loaded.Progress(vm)
+		assert.Equal(t, 1, called)
+		assert.False(t, deleted)
+		// a deleted resource is waited for until it is gone
+		assert.Len(t, loaded.Remaining(), 4)
+		require.NoError(t, loaded.Record(plan.Steps[1], graph.Gone, "", nil))
+		assert.True(t, loaded.Gone(plan.Steps[1]))
+		assert.Len(t, loaded.Remaining(), 3)
+		require.NoError(t, loaded.Remove())
+		_, err = graph.LoadJournal(path)
+		require.Error(t, err)
+
+		var none *graph.Journal
+		require.NoError(t, none.Record(vm, graph.Called, "Delete", nil))
+		called, _, _ = none.Progress(vm)
+		assert.Zero(t, called)
+	})
+	t.Run("Errors are cleared once a failed step progresses", func(t *testing.T) {
+		plan, err := g.Plan("iaas")
+		require.NoError(t, err)
+		j, err := graph.NewJournal(filepath.Join(t.TempDir(), "teardown", "net-vpc-foo.json"), plan)
+		require.NoError(t, err)
+		s := plan.Steps[1]
+		require.NoError(t, j.Record(s, graph.Failed, "Delete", errors.New("conflict")))
+		_, _, lastErr := j.Progress(s)
+		assert.Equal(t, "conflict", lastErr)
+		// resumed
+		require.NoError(t, j.Record(s, graph.Called, "Delete", nil))
+		called, _, lastErr := j.Progress(s)
+		assert.Equal(t, 1, called)
+		assert.Empty(t, lastErr)
+		require.NoError(t, j.Record(s, graph.Failed, "Delete", errors.New("timeout")))
+		require.NoError(t, j.Record(s, graph.Deleted, "", nil))
+		_, deleted, lastErr := j.Progress(s)
+		assert.True(t, deleted)
+		assert.Empty(t, lastErr)
+	})
+	t.Run("Cycles are reported", func(t *testing.T) {
+		g.DependsOn(net, vm)
+		_, err := g.Phases()
+		require.Error(t, err)
+	})
+}
+
+func TestExport(t *testing.T) {
+	g := graph.New()
+	net := g.Add(nil, node("net", "vpc-foo"))
+	subnet := g.Add(net, node("subnet", "subnet-foo"))
+	vm := g.Add(subnet, node("vm", "i-foo"))
+	sg := g.Add(net, &graph.Node{Type: "security group", ID: "sg-foo", Name: "web"})
+	g.Use(vm, node("security group", "sg-foo"))
+
+	t.Run("Links are added when a resource is used again", func(t *testing.T) {
+		require.Len(t, g.Links(), 1)
+		assert.Same(t, vm, g.Links()[0].From)
+		assert.Same(t, sg, g.Links()[0].To)
+	})
+	t.Run("Trees contain children and links", func(t *testing.T) {
+		trees := g.Trees()
+		require.Len(t, trees, 1)
+		buf, err := json.Marshal(trees[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Type":"net","Id":"vpc-foo","Children":[
+			{"Type":"subnet","Id":"subnet-foo","Children":[
+				{"Type":"vm","Id":"i-foo","Links":[{"Type":"security group","Id":"sg-foo","Name":"web"}]}
+			]},
+			{"Type":"security group","Id":"sg-foo","Name":"web"}
+		]}`, string(buf))
+	})
+	t.Run("Graphs are exported as DOT", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, g.WriteDOT(buf))
+		assert.Equal(t, `digraph {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="net\nvpc-foo"];
+  n1 [label="subnet\nsubnet-foo"];
+  n2 [label="vm\ni-foo"];
+  n3 [label="security group\nsg-foo (web)"];
+  n0 -> n1;
+  n0 -> n3;
+  n1 -> n2;
+  n2 -> n3 [style=dashed];
+}
+`, buf.String())
+	})
+	t.Run("Graphs are exported as Mermaid", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, g.WriteMermaid(buf))
+		assert.Equal(t, `flowchart LR
+  n0["net<br/>vpc-foo"]
+  n1["subnet<br/>subnet-foo"]
+  n2["vm<br/>i-foo"]
+  n3["security group<br/>sg-foo (web)"]
+  n0 --> n1
+  n0 --> n3
+  n1 --> n2
+  n2 -.-> n3
+`, buf.String())
+	})
+}
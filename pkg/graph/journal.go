@@ -0,0 +1,169 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventType is the type of a journal event.
+type EventType string
+
+const (
+	// Called is recorded each time a call of a step has succeeded.
+	Called EventType = "called"
+	// Deleted is recorded once all calls of a step have succeeded, including retries waiting for dependents to be gone.
+	Deleted EventType = "deleted"
+	// Gone is recorded once the resource of a deleted step can no longer be read, completing the step.
+	Gone EventType = "gone"
+	// Failed is recorded when a step has failed or timed out.
+	Failed EventType = "failed"
+)
+
+// Event is an event of a teardown.
+type Event struct {
+	Time     time.Time `json:"Time"`
+	Resource Resource  `json:"Resource"`
+	Type     EventType `json:"Type"`
+	Call     string    `json:"Call,omitempty"`
+	Error    string    `json:"Error,omitempty"`
+}
+
+// Journal records the progress of the execution of a plan, to resume it if it is interrupted.
+// The journal file is written after each event.
+type Journal struct {
+	Plan   *Plan   `json:"Plan"`
+	Events []Event `json:"Events"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// NewJournal creates the journal of a plan, stored at path.
+func NewJournal(path string, plan *Plan) (*Journal, error) {
+	j := &Journal{Plan: plan, Events: []Event{}, path: path}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("write journal: %w", err)
+	}
+	return j, j.save()
+}
+
+// LoadJournal loads a journal file.
+func LoadJournal(path string) (*Journal, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	j := &Journal{path: path}
+	if err := json.Unmarshal(buf, j); err != nil {
+		return nil, fmt.Errorf("read journal %s: %w", path, err)
+	}
+	if j.Plan == nil {
+		return nil, fmt.Errorf("read journal %s: no plan found", path)
+	}
+	return j, nil
+}
+
+// Path returns the path of the journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record records an event, and writes the journal file. A nil journal records nothing.
+func (j *Journal) Record(s Step, typ EventType, call string, err error) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e := Event{Time: time.Now().UTC(), Resource: s.Resource(), Type: typ, Call: call}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	j.Events = append(j.Events, e)
+	return j.save()
+}
+
+// Progress returns the number of calls of a step already made, whether the step is completed, and the last error of the step.
+// An error is cleared by later progress of the step, e.g. once resumed.
+func (j *Journal) Progress(s Step) (called int, deleted bool, lastErr string) {
+	if j == nil {
+		return 0, false, ""
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r := s.Resource()
+	for _, e := range j.Events {
+		if e.Resource.Type != r.Type || e.Resource.ID != r.ID {
+			continue
+		}
+		switch e.Type {
+		case Called:
+			called++
+			lastErr = ""
+		case Deleted:
+			deleted = true
+			lastErr = ""
+		case Failed:
+			lastErr = e.Error
+		}
+	}
+	return min(called, len(s.Calls)), deleted, lastErr
+}
+
+// Gone checks if the resource of a step is gone, the step being completed.
+func (j *Journal) Gone(s Step) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r := s.Resource()
+	return slices.ContainsFunc(j.Events, func(e Event) bool {
+		return e.Type == Gone && e.Resource.Type == r.Type && e.Resource.ID == r.ID
+	})
+}
+
+// Remaining returns the steps which have not been completed.
+func (j *Journal) Remaining() []Step {
+	var steps []Step
+	for _, s := range j.Plan.Steps {
+		if !j.Gone(s) {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// Remove removes the journal file, once the plan is completed.
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) save() error {
+	buf, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	// the journal is replaced atomically, so that an interruption never leaves a truncated file
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package graph
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Call is an API call, having the JSON representation of its request as parameters.
+type Call struct {
+	Call   string `json:"Call"`
+	Params any    `json:"Params"`
+}
+
+// Resource identifies a resource.
+type Resource struct {
+	Type Type   `json:"Type"`
+	ID   string `json:"Id"`
+	Name string `json:"Name,omitempty"`
+}
+
+func (r Resource) String() string {
+	n := Node{Type: r.Type, ID: r.ID, Name: r.Name}
+	return n.String()
+}
+
+// Step is the deletion of a resource.
+type Step struct {
+	// Phase is the phase of the deletion, starting at 1.
+	Phase int    `json:"Phase"`
+	Type  Type   `json:"Type"`
+	ID    string `json:"Id"`
+	Name  string `json:"Name,omitempty"`
+	Calls []Call `json:"Calls"`
+}
+
+func (s Step) String() string {
+	return s.Resource().String()
+}
+
+// Resource returns the resource deleted by the step.
+func (s Step) Resource() Resource {
+	return Resource{Type: s.Type, ID: s.ID, Name: s.Name}
+}
+
+// Plan is the ordered list of deletions tearing down a resource.
+type Plan struct {
+	// Service is the service of all calls, e.g. iaas.
+	Service string `json:"Service"`
+	// Resource is the resource torn down.
+	Resource Resource `json:"Resource"`
+	Steps    []Step   `json:"Steps"`
+}
+
+// Plan returns the plan deleting all resources of the graph, using the API of service.
+func (g *Graph) Plan(service string) (*Plan, error) {
+	phases, err := g.Phases()
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{Service: service, Steps: []Step{}}
+	if len(g.roots) > 0 {
+		p.Resource = Resource{Type: g.roots[0].Type, ID: g.roots[0].ID, Name: g.roots[0].Name}
+	}
+	for i, phase := range phases {
+		for _, n := range phase {
+			p.Steps = append(p.Steps, Step{Phase: i + 1, Type: n.Type, ID: n.ID, Name: n.Name, Calls: n.Calls})
+		}
+	}
+	return p, nil
+}
+
+// Phases returns the steps of the plan, grouped by phase.
+func (p *Plan) Phases() [][]Step {
+	var phases [][]Step
+	for _, s := range p.Steps {
+		for len(phases) < s.Phase {
+			phases = append(phases, nil)
+		}
+		phases[s.Phase-1] = append(phases[s.Phase-1], s)
+	}
+	return phases
+}
+
+// LoadPlan loads a plan written in JSON or YAML.
+func LoadPlan(path string) (*Plan, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+	p := &Plan{}
+	if err := yaml.Unmarshal(buf, p); err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+	for _, s := range p.Steps {
+		if s.Phase < 1 {
+			return nil, fmt.Errorf("read plan: invalid phase %d for %s", s.Phase, s)
+		}
+		if len(s.Calls) == 0 || slices.ContainsFunc(s.Calls, func(c Call) bool { return strings.TrimSpace(c.Call) == "" }) {
+			return nil, fmt.Errorf("read plan: missing call for %s", s)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,152 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package manifest reads manifests describing a set of resources, having symbolic references between them.
+//
+// A reference has the ${kind.name.Attribute} syntax, and is replaced by the attribute of the referenced resource,
+// once created.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+type Manifest struct {
+	Resources []*Resource `yaml:"resources"`
+}
+
+// Resource is a resource to create.
+type Resource struct {
+	// Kind is the kind of resource, e.g. net.
+	Kind string `yaml:"kind"`
+	// Name identifies the resource within its kind, in references.
+	Name string `yaml:"name"`
+	// Spec is the body of the creation call.
+	Spec map[string]any `yaml:"spec"`
+	// Tags are added to the resource, once created.
+	Tags map[string]string `yaml:"tags"`
+	// Calls are additional calls, made once the resource is created, e.g. to link it to another resource.
+	Calls []Call `yaml:"calls"`
+}
+
+// Call is an API call.
+type Call struct {
+	Call string         `yaml:"call"`
+	Spec map[string]any `yaml:"spec"`
+}
+
+// Ref returns the reference of the resource, in the kind.name format.
+func (r *Resource) Ref() string {
+	return r.Kind + "." + r.Name
+}
+
+func (r *Resource) String() string {
+	return r.Ref()
+}
+
+var reference = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+\.[A-Za-z0-9_-]+)\.([A-Za-z0-9_.-]+)\}`)
+
+// Dependencies returns the references of the resources referenced by r, in order of appearance.
+func (r *Resource) Dependencies() []string {
+	var deps []string
+	add := func(v any) {
+		walk(v, func(s string) {
+			for _, m := range reference.FindAllStringSubmatch(s, -1) {
+				if m[1] != r.Ref() && !slices.Contains(deps, m[1]) {
+					deps = append(deps, m[1])
+				}
+			}
+		})
+	}
+	add(r.Spec)
+	for _, c := range r.Calls {
+		add(c.Spec)
+	}
+	return deps
+}
+
+func walk(v any, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case map[string]any:
+		for _, sv := range v {
+			walk(sv, fn)
+		}
+	case []any:
+		for _, sv := range v {
+			walk(sv, fn)
+		}
+	}
+}
+
+// Load loads a manifest file.
+func Load(path string) (*Manifest, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return m, m.validate()
+}
+
+func (m *Manifest) validate() error {
+	refs := map[string]bool{}
+	for i, r := range m.Resources {
+		if r.Kind == "" || r.Name == "" {
+			return fmt.Errorf("resource #%d: kind and name are required", i+1)
+		}
+		if refs[r.Ref()] {
+			return fmt.Errorf("%s is defined twice", r)
+		}
+		refs[r.Ref()] = true
+		for _, c := range r.Calls {
+			if c.Call == "" {
+				return fmt.Errorf("%s: call is required for all calls", r)
+			}
+		}
+	}
+	for _, r := range m.Resources {
+		for _, dep := range r.Dependencies() {
+			if !refs[dep] {
+				return fmt.Errorf("%s references unknown resource %s", r, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// Order returns the resources, sorted so that each resource comes after the resources it references.
+// When several resources can be created, the one having the lowest rank is chosen first, then the first one in the manifest.
+func (m *Manifest) Order(rank func(kind string) int) ([]*Resource, error) {
+	remaining := slices.Clone(m.Resources)
+	created := map[string]bool{}
+	ordered := make([]*Resource, 0, len(remaining))
+	for len(remaining) > 0 {
+		next := -1
+		for i, r := range remaining {
+			if !slices.ContainsFunc(r.Dependencies(), func(dep string) bool { return !created[dep] }) &&
+				(next < 0 || rank(r.Kind) < rank(remaining[next].Kind)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("dependency cycle between %v", remaining)
+		}
+		created[remaining[next].Ref()] = true
+		ordered = append(ordered, remaining[next])
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+	return ordered, nil
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/outscale/octl/pkg/manifest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stack = `
+resources:
+  - kind: routeTable
+    name: public
+    spec:
+      NetId: ${net.main.NetId}
+    calls:
+      - call: LinkRouteTable
+        spec:
+          RouteTableId: ${routeTable.public.RouteTableId}
+          SubnetId: ${subnet.public.SubnetId}
+  - kind: subnet
+    name: public
+    spec:
+      NetId: ${net.main.NetId}
+      IpRange: 10.0.1.0/24
+  - kind: securityGroup
+    name: web
+    spec:
+      SecurityGroupName: web-${net.main.NetId}
+      Description: web
+  - kind: net
+    name: main
+    spec:
+      IpRange: 10.0.0.0/16
+    tags:
+      Name: main
+`
+
+func load(t *testing.T, content string) (*manifest.Manifest, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stack.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+	return manifest.Load(path)
+}
+
+func TestManifest(t *testing.T) {
+	m, err := load(t, stack)
+	require.NoError(t, err)
+	rank := func(kind string) int {
+		return map[string]int{"net": 0, "subnet": 1, "securityGroup": 2, "routeTable": 3}[kind]
+	}
+
+	t.Run("Resources are ordered by dependencies then rank", func(t *testing.T) {
+		order, err := m.Order(rank)
+		require.NoError(t, err)
+		refs := lo.Map(order, func(r *manifest.Resource, _ int) string { return r.Ref() })
+		assert.Equal(t, []string{"net.main", "subnet.public", "securityGroup.web", "routeTable.public"}, refs)
+	})
+	t.Run("References are resolved", func(t *testing.T) {
+		state := manifest.State{
+			"net.main":      {"NetId": "vpc-foo", "Size": 2, "Nics": []any{map[string]any{"NicId": "eni-foo"}}},
+			"subnet.public": {"SubnetId": "subnet-foo"},
+		}
+		v, err := state.Resolve(map[string]any{
+			"NetId":  "${net.main.NetId}",
+			"Name":   "web-${net.main.NetId}",
+			"Size":   "${net.main.Size}",
+			"NicIds": []any{"${net.main.Nics.0.NicId}"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"NetId": "vpc-foo", "Name": "web-vpc-foo", "Size": 2, "NicIds": []any{"eni-foo"}}, v)
+		_, err = state.Resolve("${net.main.Foo}")
+		require.Error(t, err)
+		_, err = state.Resolve("${routeTable.public.RouteTableId}")
+		require.Error(t, err)
+	})
+	t.Run("Invalid manifests are rejected", func(t *testing.T) {
+		_, err := load(t, "resources:\n  - kind: net\n    name: a\n  - kind: net\n    name: a\n")
+		require.Error(t, err)
+		_, err = load(t, "resources:\n  - kind: subnet\n    name: a\n    spec:\n      NetId: ${net.main.NetId}\n")
+		require.Error(t, err)
+		m, err := load(t, "resources:\n  - kind: net\n    name: a\n    spec:\n      Foo: ${net.b.NetId}\n  - kind: net\n    name: b\n    spec:\n      Foo: ${net.a.NetId}\n")
+		require.NoError(t, err)
+		_, err = m.Order(rank)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// State stores the resources already created, by reference.
+type State map[string]map[string]any
+
+// Resolve returns a copy of v, having all references replaced.
+// A string only made of a reference is replaced by the value of the attribute, keeping its type.
+func (s State) Resolve(v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return s.resolveString(v)
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, sv := range v {
+			rv, err := s.Resolve(sv)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = rv
+		}
+		return res, nil
+	case []any:
+		res := make([]any, 0, len(v))
+		for _, sv := range v {
+			rv, err := s.Resolve(sv)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, rv)
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+func (s State) resolveString(str string) (any, error) {
+	if m := reference.FindStringSubmatchIndex(str); m != nil && m[0] == 0 && m[1] == len(str) {
+		return s.lookup(str[m[2]:m[3]], str[m[4]:m[5]])
+	}
+	var err error
+	res := reference.ReplaceAllStringFunc(str, func(ref string) string {
+		m := reference.FindStringSubmatch(ref)
+		v, lerr := s.lookup(m[1], m[2])
+		if lerr != nil {
+			err = lerr
+			return ref
+		}
+		return fmt.Sprint(v)
+	})
+	return res, err
+}
+
+// lookup returns an attribute of a resource. Nested attributes are separated by dots, e.g. Nics.0.NicId.
+func (s State) lookup(ref, attr string) (any, error) {
+	res, found := s[ref]
+	if !found {
+		return nil, fmt.Errorf("%s is not created", ref)
+	}
+	var v any = res
+	for part := range strings.SplitSeq(attr, ".") {
+		switch cur := v.(type) {
+		case map[string]any:
+			v, found = cur[part]
+		case []any:
+			idx, err := strconv.Atoi(part)
+			found = err == nil && idx >= 0 && idx < len(cur)
+			if found {
+				v = cur[idx]
+			}
+		default:
+			found = false
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no %s attribute", ref, attr)
+		}
+	}
+	return v, nil
+}
@@ -6,10 +6,31 @@ SPDX-License-Identifier: BSD-3-Clause
 package messages
 
 import (
+	"fmt"
 	"os"
+	"sync/atomic"
 )
 
+// catching is set while CatchExit runs, exits being reported to it instead of stopping the process.
+var catching atomic.Bool
+
+// ExitError is an exit caught by CatchExit.
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Message
+}
+
 func Exit(code int, format string, a ...any) {
+	if catching.Load() {
+		panic(&ExitError{Code: code, Message: fmt.Sprintf(format, a...)})
+	}
 	Err(format, a...)
 	os.Exit(code)
 }
@@ -17,3 +38,29 @@ func Exit(code int, format string, a ...any) {
 func ExitErr(err error) {
 	Exit(1, "an error occurred: %v", err)
 }
+
+// Quit exits without a message, the error having already been displayed.
+func Quit(code int) {
+	if catching.Load() {
+		panic(&ExitError{Code: code})
+	}
+	os.Exit(code)
+}
+
+// CatchExit calls f, returning the exits called by f as an *ExitError instead of stopping the process.
+func CatchExit(f func() error) (err error) {
+	catching.Store(true)
+	defer catching.Store(false)
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		exitErr, ok := r.(*ExitError)
+		if !ok {
+			panic(r)
+		}
+		err = exitErr
+	}()
+	return f()
+}
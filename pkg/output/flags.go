@@ -52,6 +52,8 @@ func NewFromFlags(fs *pflag.FlagSet, out, contentField string, cols config.Colum
 		fmter = format.None{}
 	case "json", "raw":
 		fmter = format.JSON{}
+	case "jsonl":
+		fmter = format.JSONL{}
 	case "yaml":
 		fmter = format.YAML{}
 	case "base64":
@@ -90,12 +92,20 @@ func NewFromFlags(fs *pflag.FlagSet, out, contentField string, cols config.Colum
 
 	writeTo, _ := fs.GetString("out-file")
 	single, _ := fs.GetBool("single")
+	maxPages, err := fs.GetInt("max-pages")
+	if err != nil {
+		maxPages = read.DefaultMaxPages
+	}
+	if all, _ := fs.GetBool("all"); all {
+		maxPages = 0
+	}
+	pageSize, _ := fs.GetInt("page-size")
 	switch {
 	case out == "raw":
 		return fmter, &Paginated{Read: read.NewRaw(), Format: fmter, Filters: filters, WriteTo: writeTo}, nil
 	case single:
-		return fmter, &Paginated{Read: read.NewPaginated(contentField), Format: format.Single{ForFormat: fmter}, Filters: filters, WriteTo: writeTo}, nil
+		return fmter, &Paginated{Read: read.NewPaginated(contentField, maxPages, pageSize), Format: format.Single{ForFormat: fmter}, Filters: filters, WriteTo: writeTo}, nil
 	default:
-		return fmter, &Paginated{Read: read.NewPaginated(contentField), Format: fmter, Filters: filters, WriteTo: writeTo}, nil
+		return fmter, &Paginated{Read: read.NewPaginated(contentField, maxPages, pageSize), Format: fmter, Filters: filters, WriteTo: writeTo}, nil
 	}
 }
@@ -9,7 +9,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
 	"reflect"
 
 	"github.com/gabriel-vasile/mimetype"
@@ -46,7 +45,7 @@ func (Body) Format(ctx context.Context, w io.Writer, v any) (err error) {
 	if !mimetype.Detect(buf).Is("text/plain") && IsTerminal(w) {
 		_ = r.Close()
 		messages.Warn("not displaying binary data to terminal, you need to redirect output to a file")
-		os.Exit(1) //nolint
+		messages.Quit(1)
 	}
 	// output first 100ish bytes
 	_, err = io.Copy(w, wbuf)
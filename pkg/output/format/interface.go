@@ -7,9 +7,17 @@ package format
 import (
 	"context"
 	"io"
+	"iter"
+
+	"github.com/outscale/octl/pkg/output/result"
 )
 
 type Interface interface {
 	Format(ctx context.Context, w io.Writer, v any) error
 	Error(ctx context.Context, v any) error
 }
+
+// Streamer is implemented by formats able to write each entry as soon as it is read, without buffering the whole output.
+type Streamer interface {
+	Stream(ctx context.Context, w io.Writer, seq iter.Seq[result.Result]) error
+}
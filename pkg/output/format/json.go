@@ -9,8 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 
+	"github.com/outscale/octl/pkg/output/result"
 	"github.com/tidwall/pretty"
 )
 
@@ -28,8 +30,48 @@ func (JSON) Format(ctx context.Context, w io.Writer, v any) error {
 	return err
 }
 
+// Stream writes the same indented array as Format, one entry at a time.
+// If an error is read, the array is closed and the error is returned.
+func (JSON) Stream(ctx context.Context, w io.Writer, seq iter.Seq[result.Result]) (err error) {
+	color := IsTerminal(w)
+	n := 0
+	for r := range seq {
+		if r.Error != nil {
+			err = r.Error
+			break
+		}
+		buf, merr := json.MarshalIndent(r.Ok, "  ", "  ")
+		if merr != nil {
+			err = fmt.Errorf("marshal json: %w", merr)
+			break
+		}
+		if color {
+			buf = pretty.Color(buf, nil)
+		}
+		sep := ",\n  "
+		if n == 0 {
+			sep = "[\n  "
+		}
+		if _, werr := fmt.Fprint(w, sep, string(buf)); werr != nil {
+			return werr
+		}
+		n++
+	}
+	end := "\n]"
+	if n == 0 {
+		end = "[]"
+	}
+	if _, werr := fmt.Fprintln(w, end); werr != nil && err == nil {
+		err = werr
+	}
+	return err
+}
+
 func (j JSON) Error(ctx context.Context, v any) error {
 	return j.Format(ctx, os.Stderr, v)
 }
 
-var _ Interface = JSON{}
+var (
+	_ Interface = JSON{}
+	_ Streamer  = JSON{}
+)
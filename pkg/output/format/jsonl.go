@@ -0,0 +1,62 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package format
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+
+	"github.com/outscale/octl/pkg/output/result"
+)
+
+// JSONL outputs JSON Lines, one compact JSON document per entry.
+type JSONL struct{}
+
+func (JSONL) Format(ctx context.Context, w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	vv := reflect.ValueOf(v)
+	if vv.Kind() != reflect.Slice {
+		return encodeLine(enc, v)
+	}
+	for i := range vv.Len() {
+		if err := encodeLine(enc, vv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (JSONL) Stream(ctx context.Context, w io.Writer, seq iter.Seq[result.Result]) error {
+	enc := json.NewEncoder(w)
+	for r := range seq {
+		if r.Error != nil {
+			return r.Error
+		}
+		if err := encodeLine(enc, r.Ok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeLine(enc *json.Encoder, v any) error {
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+	return nil
+}
+
+func (JSONL) Error(ctx context.Context, v any) error {
+	return JSON{}.Error(ctx, v)
+}
+
+var (
+	_ Interface = JSONL{}
+	_ Streamer  = JSONL{}
+)
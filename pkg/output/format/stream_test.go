@@ -0,0 +1,65 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package format_test
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/output/format"
+	"github.com/outscale/octl/pkg/output/result"
+	"github.com/outscale/osc-sdk-go/v3/pkg/osc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func values(vs ...any) iter.Seq[result.Result] {
+	return func(yield func(result.Result) bool) {
+		for _, v := range vs {
+			if !yield(result.Result{Ok: v}) {
+				return
+			}
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	vms := []any{
+		osc.Vm{VmId: "i-foo", BsuOptimized: new(true), Tags: []osc.ResourceTag{{Key: "Name", Value: "foo"}}},
+		osc.Vm{VmId: "i-bar", BsuOptimized: new(false)},
+	}
+	cols := config.Columns{{Title: "ID", Content: ".VmId"}, {Title: "BsuOptimized", Content: ".BsuOptimized"}}
+	formats := map[string]interface {
+		format.Interface
+		format.Streamer
+	}{
+		"json":  format.JSON{},
+		"jsonl": format.JSONL{},
+		"yaml":  format.YAML{},
+		"csv":   format.Tabular{Columns: cols, Formatter: format.CSVFormatter{}},
+	}
+	for name, f := range formats {
+		t.Run(name+" stream is identical to format", func(t *testing.T) {
+			for _, vs := range [][]any{vms, {}} {
+				buffered, streamed := &bytes.Buffer{}, &bytes.Buffer{}
+				err := f.Format(t.Context(), buffered, vs)
+				require.NoError(t, err)
+				err = f.Stream(t.Context(), streamed, values(vs...))
+				require.NoError(t, err)
+				assert.Equal(t, buffered.String(), streamed.String())
+			}
+		})
+		t.Run(name+" stream returns read errors", func(t *testing.T) {
+			seq := func(yield func(result.Result) bool) {
+				_ = yield(result.Result{Ok: vms[0]}) && yield(result.Result{Error: errors.New("fail")})
+			}
+			err := f.Stream(t.Context(), &bytes.Buffer{}, seq)
+			require.EqualError(t, err, "fail")
+		})
+	}
+}
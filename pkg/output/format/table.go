@@ -10,6 +10,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"reflect"
 	"slices"
@@ -20,6 +21,7 @@ import (
 	"github.com/outscale/octl/pkg/config"
 	"github.com/outscale/octl/pkg/debug"
 	"github.com/outscale/octl/pkg/messages"
+	"github.com/outscale/octl/pkg/output/result"
 	"github.com/outscale/octl/pkg/style"
 	"github.com/samber/lo"
 )
@@ -31,6 +33,7 @@ var colors = map[string]map[string]lipgloss.Style{
 		"ACTIVE":    style.Green,
 		"InService": style.Green,
 		"attached":  style.Green,
+		"succeeded": style.Green,
 
 		"pending":       style.Yellow,
 		"stopping":      style.Yellow,
@@ -61,12 +64,24 @@ var colors = map[string]map[string]lipgloss.Style{
 
 		"failed": style.Red,
 	},
+	"Severity": {
+		"low": style.Faint,
+
+		"medium": style.Yellow,
+
+		"high": style.Red,
+	},
 }
 
 type TabularFormatter interface {
 	Format(ctx context.Context, w io.Writer, header []string, data [][]string) error
 }
 
+// StreamingTabularFormatter is implemented by tabular formatters able to write rows one at a time.
+type StreamingTabularFormatter interface {
+	Stream(ctx context.Context, w io.Writer, header []string, rows iter.Seq2[[]string, error]) error
+}
+
 type Tabular struct {
 	Explode, Sort bool
 	Columns       config.Columns
@@ -124,16 +139,7 @@ func (t Tabular) Format(ctx context.Context, w io.Writer, v any) error {
 	}
 	// styling
 	for r := range rows {
-		for c := range rows[r] {
-			styles, found := colors[headers[c]]
-			if !found {
-				continue
-			}
-			vstyle, found := styles[rows[r][c]]
-			if found {
-				rows[r][c] = vstyle.Render(rows[r][c])
-			}
-		}
+		styleRow(headers, rows[r])
 	}
 	// sort
 	if t.Sort {
@@ -155,6 +161,64 @@ func (t Tabular) Format(ctx context.Context, w io.Writer, v any) error {
 	return t.Formatter.Format(ctx, w, headers, rows)
 }
 
+// Stream writes rows as soon as entries are read, if the formatter supports it and no sort is required.
+// Otherwise, all entries are read before formatting.
+func (t Tabular) Stream(ctx context.Context, w io.Writer, seq iter.Seq[result.Result]) error {
+	sf, ok := t.Formatter.(StreamingTabularFormatter)
+	if !ok || t.Sort {
+		var values []any
+		for r := range seq {
+			if r.Error != nil {
+				return r.Error
+			}
+			values = append(values, r.Ok)
+		}
+		return t.Format(ctx, w, values)
+	}
+	first, found, seq, stop := result.Peek(seq)
+	defer stop()
+	if found && first.Error == nil && !validForTable(first.Ok) {
+		messages.Info("Unable to format as a table, switching to YAML...")
+		return YAML{}.Stream(ctx, w, seq)
+	}
+	headers := lo.Map(t.Columns, func(c config.Column, _ int) string {
+		return c.Title
+	})
+	rows := func(yield func([]string, error) bool) {
+		for r := range seq {
+			if r.Error != nil {
+				_ = yield(nil, r.Error)
+				return
+			}
+			add, err := GetRows(r.Ok, t.Columns, t.Explode)
+			if err != nil {
+				_ = yield(nil, err)
+				return
+			}
+			for _, row := range add {
+				styleRow(headers, row)
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+	}
+	return sf.Stream(ctx, w, headers, rows)
+}
+
+func styleRow(headers, row []string) {
+	for c := range row {
+		styles, found := colors[headers[c]]
+		if !found {
+			continue
+		}
+		vstyle, found := styles[row[c]]
+		if found {
+			row[c] = vstyle.Render(row[c])
+		}
+	}
+}
+
 func (Tabular) Error(ctx context.Context, v any) error {
 	return YAML{}.Error(ctx, v)
 }
@@ -227,3 +291,32 @@ func (f CSVFormatter) Format(ctx context.Context, w io.Writer, headers []string,
 	}
 	return nil
 }
+
+func (f CSVFormatter) Stream(ctx context.Context, w io.Writer, headers []string, rows iter.Seq2[[]string, error]) error {
+	cw := csv.NewWriter(w)
+	defer func() {
+		cw.Flush()
+	}()
+
+	err := cw.Write(headers)
+	if err != nil {
+		return err
+	}
+	for row, err := range rows {
+		if err != nil {
+			return err
+		}
+		err = cw.Write(row)
+		if err != nil {
+			return err
+		}
+		cw.Flush()
+	}
+	return nil
+}
+
+var (
+	_ Interface                 = Tabular{}
+	_ Streamer                  = Tabular{}
+	_ StreamingTabularFormatter = CSVFormatter{}
+)
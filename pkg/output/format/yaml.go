@@ -8,9 +8,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 
 	"github.com/goccy/go-yaml"
+	"github.com/outscale/octl/pkg/output/result"
 )
 
 type YAML struct{}
@@ -24,8 +26,31 @@ func (YAML) Format(ctx context.Context, w io.Writer, v any) error {
 	return enc.Close()
 }
 
+// Stream writes the same sequence as Format, one entry at a time.
+func (y YAML) Stream(ctx context.Context, w io.Writer, seq iter.Seq[result.Result]) error {
+	empty := true
+	for r := range seq {
+		if r.Error != nil {
+			return r.Error
+		}
+		// encoding a single item sequence outputs a `- ` prefixed block, which can be concatenated to the previous ones
+		err := y.Format(ctx, w, []any{r.Ok})
+		if err != nil {
+			return err
+		}
+		empty = false
+	}
+	if empty {
+		return y.Format(ctx, w, []any{})
+	}
+	return nil
+}
+
 func (y YAML) Error(ctx context.Context, v any) error {
 	return y.Format(ctx, os.Stderr, v)
 }
 
-var _ Interface = YAML{}
+var (
+	_ Interface = YAML{}
+	_ Streamer  = YAML{}
+)
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"slices"
 
@@ -55,6 +56,9 @@ func (p *Paginated) Output(ctx context.Context, fetch read.FetchPage) (err error
 	for _, f := range p.Filters {
 		seq = f.Filter(ctx, seq)
 	}
+	if s, ok := p.Format.(format.Streamer); ok {
+		return p.stream(ctx, writeTo, s, seq)
+	}
 	res := slices.Collect(seq)
 	errRes, found := lo.Find(res, func(r result.Result) bool {
 		return r.Error != nil
@@ -68,6 +72,20 @@ func (p *Paginated) Output(ctx context.Context, fetch read.FetchPage) (err error
 	return p.Format.Format(ctx, writeTo, lo.Map(res, func(r result.Result, _ int) any { return r.Ok }))
 }
 
+// stream writes entries as they are read, except for single entries which are not wrapped in a list.
+func (p *Paginated) stream(ctx context.Context, w io.Writer, s format.Streamer, seq iter.Seq[result.Result]) error {
+	first, found, seq, stop := result.Peek(seq)
+	defer stop()
+	switch {
+	case found && first.Error != nil:
+		return first.Error
+	case found && first.SingleEntry:
+		// readers do not yield anything after a single entry
+		return p.Format.Format(ctx, w, first.Ok)
+	}
+	return s.Stream(ctx, w, seq)
+}
+
 func (p *Paginated) Error(ctx context.Context, v any) error {
 	return p.Format.Error(ctx, v)
 }
@@ -17,12 +17,14 @@ import (
 type FetchPage struct {
 	Method reflect.Value
 	Args   []reflect.Value
+	// Quiet disables the spinner displayed during long calls, e.g. when pages are fetched concurrently.
+	Quiet bool
 }
 
 func (f *FetchPage) Call(ctx context.Context) []reflect.Value {
 	// display a spinner if API call lasts more than 200ms
 	stopSpinner := func() {}
-	if isatty.IsTerminal(os.Stderr.Fd()) {
+	if !f.Quiet && isatty.IsTerminal(os.Stderr.Fd()) {
 		t := time.AfterFunc(200*time.Millisecond, func() {
 			stopSpinner = spinner.Run(ctx, "Waiting for server...")
 		})
@@ -13,6 +13,8 @@ import (
 type Pager interface {
 	HasMore(res reflect.Value) bool
 	NextItem(res reflect.Value, fetch FetchPage, nextIndex int) (FetchPage, bool)
+	// SetPageSize sets the number of items returned by each call, and returns false if the call does not support it.
+	SetPageSize(fetch FetchPage, size int) bool
 }
 
 func PagerFor(fetch FetchPage) Pager {
@@ -40,3 +42,27 @@ func PagerFor(fetch FetchPage) Pager {
 	debug.Println("no paging")
 	return NonePager{}
 }
+
+// setIntArg sets the first int field named name in the args of fetch.
+func setIntArg(fetch FetchPage, name string, value int) bool {
+	for _, arg := range fetch.Args {
+		arg = reflect.Indirect(arg)
+		if arg.Kind() != reflect.Struct {
+			continue
+		}
+		field := arg.FieldByName(name)
+		if !field.IsValid() {
+			continue
+		}
+		if field.Kind() == reflect.Pointer && field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = reflect.Indirect(field)
+		if !field.CanInt() {
+			continue
+		}
+		field.SetInt(int64(value))
+		return true
+	}
+	return false
+}
@@ -44,4 +44,8 @@ func (FirstItemPager) NextItem(res reflect.Value, fetch FetchPage, nextIndex int
 	return fetch, false
 }
 
+func (FirstItemPager) SetPageSize(fetch FetchPage, size int) bool {
+	return setIntArg(fetch, "ResultsPerPage", size)
+}
+
 var _ Pager = FirstItemPager{}
@@ -17,4 +17,8 @@ func (p NonePager) NextItem(res reflect.Value, fetch FetchPage, _ int) (FetchPag
 	return fetch, false
 }
 
+func (p NonePager) SetPageSize(fetch FetchPage, size int) bool {
+	return false
+}
+
 var _ Pager = NonePager{}
@@ -65,4 +65,8 @@ func (p OOSPager) NextItem(res reflect.Value, fetch FetchPage, _ int) (FetchPage
 	return fetch, false
 }
 
+func (OOSPager) SetPageSize(fetch FetchPage, size int) bool {
+	return setIntArg(fetch, "MaxKeys", size)
+}
+
 var _ Pager = OOSPager{}
@@ -57,4 +57,8 @@ func (p TokenPager) NextItem(res reflect.Value, fetch FetchPage, _ int) (FetchPa
 	return fetch, false
 }
 
+func (TokenPager) SetPageSize(fetch FetchPage, size int) bool {
+	return setIntArg(fetch, "ResultsPerPage", size)
+}
+
 var _ Pager = TokenPager{}
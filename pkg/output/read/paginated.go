@@ -13,27 +13,37 @@ import (
 	"strings"
 
 	"github.com/outscale/octl/pkg/debug"
+	"github.com/outscale/octl/pkg/messages"
 	"github.com/outscale/octl/pkg/output/result"
 )
 
-const MaxPages = 20
+// DefaultMaxPages is the default number of pages fetched by a listing.
+const DefaultMaxPages = 20
 
 type Paginated struct {
 	contentField string
+	maxPages     int
+	pageSize     int
 }
 
-func NewPaginated(contentField string) *Paginated {
+// NewPaginated returns a reader fetching at most maxPages pages (no limit if 0) of pageSize items (API default if 0).
+func NewPaginated(contentField string, maxPages, pageSize int) *Paginated {
 	return &Paginated{
 		contentField: contentField,
+		maxPages:     maxPages,
+		pageSize:     pageSize,
 	}
 }
 
 func (p *Paginated) Read(ctx context.Context, fetch FetchPage) iter.Seq[result.Result] {
 	pager := PagerFor(fetch)
+	if p.pageSize > 0 && !pager.SetPageSize(fetch, p.pageSize) {
+		messages.Warn("--page-size is not supported by this call, ignoring")
+	}
 	return func(yield func(result.Result) bool) {
 		fetch := fetch
 		idx := 0
-		for range MaxPages {
+		for page := 1; ; page++ {
 			vres := fetch.Call(ctx)
 			if len(vres) == 0 {
 				_ = yield(result.Result{Error: errors.New("no result from call")})
@@ -75,6 +85,10 @@ func (p *Paginated) Read(ctx context.Context, fetch FetchPage) iter.Seq[result.R
 				debug.Println("end of list...")
 				return
 			}
+			if p.maxPages > 0 && page >= p.maxPages {
+				messages.Warn("Listing truncated after %d page(s) and %d item(s), use --max-pages or --all to fetch more", page, idx)
+				return
+			}
 			debug.Println("has more items...")
 			var ok bool
 			fetch, ok = pager.NextItem(res, fetch, idx)
@@ -0,0 +1,22 @@
+package result
+
+import "iter"
+
+// Peek returns the first result of seq, and a sequence yielding this first result followed by the remaining ones.
+// stop must be called once the returned sequence is no longer used.
+func Peek(seq iter.Seq[Result]) (first Result, found bool, all iter.Seq[Result], stop func()) {
+	next, stop := iter.Pull(seq)
+	first, found = next()
+	all = func(yield func(Result) bool) {
+		if !found || !yield(first) {
+			return
+		}
+		for {
+			r, ok := next()
+			if !ok || !yield(r) {
+				return
+			}
+		}
+	}
+	return first, found, all, stop
+}
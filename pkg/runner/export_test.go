@@ -0,0 +1,13 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package runner
+
+import "reflect"
+
+// PlanFields returns the fields of the resource and of the request compared by a plan, and the fields left out.
+func PlanFields(ct reflect.Type, read, content string, res, fields map[string]any) (current, requested map[string]any, skipped []string) {
+	return matchFields(res, resourceFields(ct, read, content), fields)
+}
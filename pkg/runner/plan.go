@@ -0,0 +1,275 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/outscale/octl/pkg/config"
+	"github.com/outscale/octl/pkg/diff"
+	"github.com/outscale/octl/pkg/messages"
+	"github.com/samber/lo"
+)
+
+// PlanFlag is the flag displaying the changes of an update call, without making it.
+const PlanFlag = "plan"
+
+// PlanCall returns the call reading the current state of the resource modified by call.
+// OAPI Update* calls are planned using the Read* call of the same entity, and OOS Put* calls using the matching Get* call.
+func PlanCall(cfg config.Config, ct reflect.Type, call string) (string, bool) {
+	if name, found := strings.CutPrefix(call, "Update"); found {
+		entity := cfg.Calls[call].Entity
+		if entity == "" || cfg.Entities[entity].Primary == "" {
+			return "", false
+		}
+		if c, found := cfg.Calls["Read"+name+"s"]; found && c.Entity == entity && c.Content != "" {
+			return "Read" + name + "s", true
+		}
+		reads := lo.Filter(lo.Keys(cfg.Calls), func(read string, _ int) bool {
+			return strings.HasPrefix(read, "Read") && cfg.Calls[read].Entity == entity && cfg.Calls[read].Content != ""
+		})
+		slices.Sort(reads)
+		if len(reads) == 0 {
+			return "", false
+		}
+		return reads[0], true
+	}
+	if name, found := strings.CutPrefix(call, "Put"); found && name != "Object" {
+		if _, found := ct.MethodByName("Get" + name); found {
+			return "Get" + name, true
+		}
+	}
+	return "", false
+}
+
+// plan displays the changes that call would make with req, without making the call.
+func plan[Client any](ctx context.Context, cl Client, cfg config.Config, call string, req reflect.Value) error {
+	read, ok := PlanCall(cfg, reflect.TypeFor[Client](), call)
+	if !ok {
+		return fmt.Errorf("--%s is not supported by %s", PlanFlag, call)
+	}
+	var (
+		current, requested any
+		err                error
+	)
+	if strings.HasPrefix(call, "Update") {
+		current, requested, err = planUpdate(ctx, cl, cfg, call, read, reflect.Indirect(req))
+	} else {
+		current, requested, err = planPut(ctx, cl, cfg, read, reflect.Indirect(req))
+	}
+	if err != nil {
+		return err
+	}
+	changes, err := diff.Compare(current, requested)
+	if err != nil {
+		return err
+	}
+	if err := diff.Write(os.Stdout, changes); err != nil {
+		return err
+	}
+	if !diff.HasChanges(changes) {
+		messages.Info("No changes")
+	}
+	return nil
+}
+
+// planUpdate reads the resource using the ID set in the request.
+func planUpdate[Client any](ctx context.Context, cl Client, cfg config.Config, call, read string, req reflect.Value) (current, requested any, err error) {
+	primary := cfg.Entities[cfg.Calls[call].Entity].Primary
+	fields, err := toMap(req.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+	readReq := map[string]any{}
+	if id, found := fields[primary]; found {
+		readReq["Filters"] = map[string]any{primary + "s": []any{id}}
+		delete(fields, primary)
+	}
+	resp, err := CallJSON(ctx, cl, read, readReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, _ := resp[cfg.Calls[read].Content].([]any)
+	if len(items) != 1 {
+		return nil, nil, fmt.Errorf("%s returned %d resources, expecting 1", read, len(items))
+	}
+	res, _ := items[0].(map[string]any)
+	current, requested, skipped := matchFields(res, resourceFields(reflect.TypeFor[Client](), read, cfg.Calls[read].Content), fields)
+	if len(skipped) > 0 {
+		messages.Info("Not compared, not returned by %s: %s", read, strings.Join(skipped, ", "))
+	}
+	return current, requested, nil
+}
+
+// resourceFields returns the JSON names of the fields of the resources listed by a Read call.
+func resourceFields(ct reflect.Type, read, content string) map[string]bool {
+	m, found := ct.MethodByName(read)
+	if !found || m.Type.NumOut() == 0 {
+		return nil
+	}
+	t := m.Type.Out(0)
+	for _, name := range []string{content, ""} {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		if name == "" {
+			break
+		}
+		f, found := t.FieldByName(name)
+		if !found {
+			return nil
+		}
+		t = f.Type
+	}
+	fields := map[string]bool{}
+	for f := range t.Fields() {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fields[lo.CoalesceOrEmpty(name, f.Name)] = true
+	}
+	return fields
+}
+
+// matchFields returns the fields of the resource matching the fields of the request, known being the fields of the
+// resource type or, if nil, the fields of res, and the request without the fields having no match, whose names are also returned.
+// A request field is matched by the resource field of the same name or, for a list of IDs (e.g. SecurityGroupIds), by the
+// IDs of the matching list of objects (e.g. SecurityGroups[].SecurityGroupId).
+func matchFields(res map[string]any, known map[string]bool, fields map[string]any) (current, requested map[string]any, skipped []string) {
+	if known == nil {
+		known = lo.MapValues(res, func(any, string) bool { return true })
+	}
+	current, requested = map[string]any{}, map[string]any{}
+	for name, value := range fields {
+		base, isList := strings.CutSuffix(name, "Ids")
+		switch {
+		case known[name]:
+			if v, found := res[name]; found {
+				current[name] = v
+			}
+		case isList && known[base+"s"]:
+			if list, ok := res[base+"s"].([]any); ok {
+				current[name] = lo.Map(list, func(item any, _ int) any {
+					obj, _ := item.(map[string]any)
+					return obj[base+"Id"]
+				})
+			}
+		default:
+			skipped = append(skipped, name)
+			continue
+		}
+		requested[name] = value
+	}
+	slices.Sort(skipped)
+	return current, requested, skipped
+}
+
+// planPut reads the configuration using the fields of the request also present in the Get request (e.g. Bucket).
+// If the request has a single configuration field (e.g. VersioningConfiguration), it is compared to the current configuration.
+func planPut[Client any](ctx context.Context, cl Client, cfg config.Config, get string, req reflect.Value) (current, requested any, err error) {
+	m := reflect.ValueOf(cl).MethodByName(get)
+	if m.Type().In(1).Kind() != reflect.Pointer {
+		return nil, nil, fmt.Errorf("unsupported %s request", get)
+	}
+	getReq := reflect.New(m.Type().In(1).Elem())
+	var ids []string
+	for i := range getReq.Elem().NumField() {
+		f := getReq.Elem().Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if v := req.FieldByName(f.Name); v.IsValid() && v.Type() == f.Type {
+			getReq.Elem().Field(i).Set(v)
+			ids = append(ids, f.Name)
+		}
+	}
+	fields, err := toMap(req.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, id := range ids {
+		delete(fields, id)
+	}
+	configs := lo.Filter(lo.Keys(fields), func(k string, _ int) bool { return strings.HasSuffix(k, "Configuration") })
+	requested = fields
+	if len(configs) == 1 {
+		requested = fields[configs[0]]
+	}
+
+	out := m.Call([]reflect.Value{reflect.ValueOf(ctx), getReq})
+	if err, ok := out[1].Interface().(error); ok && err != nil {
+		// no configuration is set
+		var apiErr interface{ ErrorCode() string }
+		if errors.As(err, &apiErr) && strings.HasPrefix(apiErr.ErrorCode(), "NoSuch") {
+			return nil, requested, nil
+		}
+		return nil, nil, err
+	}
+	resp, err := toMap(out[0].Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(resp, "ResultMetadata")
+	if content := cfg.Calls[get].Content; content != "" {
+		return resp[content], requested, nil
+	}
+	return resp, requested, nil
+}
+
+// toMap returns the JSON representation of v, without null and empty string values.
+func toMap(v any) (map[string]any, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return nil, err
+	}
+	for k, v := range res {
+		if v == nil || v == "" {
+			delete(res, k)
+		}
+	}
+	return res, nil
+}
+
+// CallJSON calls a method of a client, having a context and a request as arguments, from the JSON representation of the request.
+// The response is returned in its JSON representation.
+func CallJSON(ctx context.Context, cl any, call string, req any) (map[string]any, error) {
+	m := reflect.ValueOf(cl).MethodByName(call)
+	if !m.IsValid() || m.Type().NumIn() < 2 || m.Type().NumOut() != 2 {
+		return nil, fmt.Errorf("unknown call %s", call)
+	}
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s request: %w", call, err)
+	}
+	arg := reflect.New(m.Type().In(1))
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(arg.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid %s request: %w", call, err)
+	}
+	out := m.Call([]reflect.Value{reflect.ValueOf(ctx), arg.Elem()})
+	if err, ok := out[1].Interface().(error); ok && err != nil {
+		return nil, err
+	}
+	resp, err := toMap(out[0].Interface())
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s response: %w", call, err)
+	}
+	return resp, nil
+}
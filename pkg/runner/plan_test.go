@@ -0,0 +1,86 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package runner_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/outscale/octl/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+type securityGroupLight struct {
+	SecurityGroupId   string `json:"SecurityGroupId"`
+	SecurityGroupName string `json:"SecurityGroupName"`
+}
+
+type vm struct {
+	VmId           string               `json:"VmId"`
+	VmType         string               `json:"VmType"`
+	KeypairName    *string              `json:"KeypairName,omitempty"`
+	SecurityGroups []securityGroupLight `json:"SecurityGroups"`
+}
+
+type readVmsResponse struct {
+	Vms *[]vm `json:"Vms,omitempty"`
+}
+
+type client struct{}
+
+func (client) ReadVms(context.Context, struct{}) (*readVmsResponse, error) { return nil, nil }
+
+func TestPlanFields(t *testing.T) {
+	res := map[string]any{
+		"VmId":   "i-foo",
+		"VmType": "tinav5.c1r1p2",
+		"SecurityGroups": []any{
+			map[string]any{"SecurityGroupId": "sg-foo", "SecurityGroupName": "foo"},
+		},
+	}
+	tcs := []struct {
+		name      string
+		fields    map[string]any
+		current   map[string]any
+		requested map[string]any
+		skipped   []string
+	}{
+		{
+			name:      "Fields of the same name are compared",
+			fields:    map[string]any{"VmType": "tinav6.c1r1p2"},
+			current:   map[string]any{"VmType": "tinav5.c1r1p2"},
+			requested: map[string]any{"VmType": "tinav6.c1r1p2"},
+		},
+		{
+			name:      "Fields without a current value are added",
+			fields:    map[string]any{"KeypairName": "foo"},
+			current:   map[string]any{},
+			requested: map[string]any{"KeypairName": "foo"},
+		},
+		{
+			name:      "Lists of IDs are compared with the IDs of lists of objects",
+			fields:    map[string]any{"SecurityGroupIds": []any{"sg-foo"}},
+			current:   map[string]any{"SecurityGroupIds": []any{"sg-foo"}},
+			requested: map[string]any{"SecurityGroupIds": []any{"sg-foo"}},
+		},
+		{
+			name:      "Fields not returned by the read call are left out",
+			fields:    map[string]any{"UserData": "Zm9v", "VmType": "tinav5.c1r1p2"},
+			current:   map[string]any{"VmType": "tinav5.c1r1p2"},
+			requested: map[string]any{"VmType": "tinav5.c1r1p2"},
+			skipped:   []string{"UserData"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			current, requested, skipped := runner.PlanFields(reflect.TypeFor[client](), "ReadVms", "Vms", res, tc.fields)
+			assert.Equal(t, tc.current, current)
+			assert.Equal(t, tc.requested, requested)
+			assert.Equal(t, tc.skipped, skipped)
+		})
+	}
+}
@@ -89,6 +89,10 @@ func doRun[Client any, Error error](cmd *cobra.Command, args []string, cl Client
 		return fmt.Errorf("too many arguments for %s", cmd.Name())
 	}
 
+	if planned, _ := cmd.Flags().GetBool(PlanFlag); planned && len(callArgs) > 1 {
+		return plan(ctx, cl, cfg, cmd.Name(), callArgs[1])
+	}
+
 	c := cfg.Calls[cmd.Name()]
 	debug.Println("call", cmd.Name())
 	e := cfg.Entities[c.Entity]
@@ -107,7 +111,7 @@ func doRun[Client any, Error error](cmd *cobra.Command, args []string, cl Client
 		if errors.As(err, &appErr) {
 			_, _ = fmt.Fprintln(os.Stderr, style.Error.Render("The server returned an error"))
 			_ = out.Error(ctx, appErr)
-			os.Exit(1)
+			messages.Quit(1)
 		}
 		return err
 	}
@@ -414,3 +418,18 @@ func setValueInterface(arg reflect.Value, fs *pflag.FlagSet, flag string) error
 	arg.Set(reflect.ValueOf(v))
 	return nil
 }
+
+// ResetFlags resets all flags set by a previous execution to their default value.
+func ResetFlags(fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if svalue, ok := f.Value.(pflag.SliceValue); ok {
+			_ = svalue.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
@@ -6,6 +6,7 @@ SPDX-License-Identifier: BSD-3-Clause
 package runner
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,6 +35,22 @@ func InjectStdin(buf []byte) {
 	stdinChecked = true
 }
 
+// StdinLines returns the lines of the standard input if it contains JSON Lines, i.e. multiple JSON documents, one per line.
+func StdinLines() ([][]byte, bool) {
+	var lines [][]byte
+	for line := range bytes.Lines(stdin) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, false
+		}
+		lines = append(lines, line)
+	}
+	return lines, len(lines) > 1
+}
+
 func CheckStdin() error {
 	stdinChecked = true
 	if isatty.IsTerminal(os.Stdin.Fd()) {
@@ -0,0 +1,180 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package sdk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goccy/go-yaml"
+)
+
+const redacted = "[REDACTED]"
+
+// Cassette stores HTTP exchanges, recorded with a Recorder and served by a Replayer.
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+type Request struct {
+	Method string      `yaml:"method"`
+	URL    string      `yaml:"url"`
+	Header http.Header `yaml:"header,omitempty"`
+	Body   Body        `yaml:",inline"`
+}
+
+type Response struct {
+	StatusCode int         `yaml:"status_code"`
+	Header     http.Header `yaml:"header,omitempty"`
+	Body       Body        `yaml:",inline"`
+}
+
+// maxBodySize is the size of the largest body stored in a cassette.
+const maxBodySize = 1 << 20
+
+// Body is the body of a request or of a response. Binary bodies are encoded in base64, and bodies larger than
+// maxBodySize are only stored by their size and their SHA-256 checksum.
+type Body struct {
+	Content  string `yaml:"body,omitempty"`
+	Encoding string `yaml:"body_encoding,omitempty"`
+	Size     int64  `yaml:"body_size,omitempty"`
+	SHA256   string `yaml:"body_sha256,omitempty"`
+}
+
+// reader returns a reader of the body, failing if its content was too large to be stored.
+func (b Body) reader() (io.Reader, int64, error) {
+	switch {
+	case b.Encoding == "base64":
+		buf, err := base64.StdEncoding.DecodeString(b.Content)
+		return bytes.NewReader(buf), int64(len(buf)), err
+	case b.Content == "" && b.Size > 0:
+		return nil, 0, fmt.Errorf("body of %d bytes not recorded", b.Size)
+	}
+	return strings.NewReader(b.Content), int64(len(b.Content)), nil
+}
+
+// bodyRecorder records a body while it is read, keeping its content only up to maxBodySize.
+type bodyRecorder struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	hash hash.Hash
+	size int64
+}
+
+func newBodyRecorder() *bodyRecorder {
+	return &bodyRecorder{hash: sha256.New()}
+}
+
+func (r *bodyRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.size += int64(len(p))
+	r.hash.Write(p)
+	if keep := maxBodySize + 1 - r.buf.Len(); keep > 0 {
+		r.buf.Write(p[:min(len(p), keep)])
+	}
+	return len(p), nil
+}
+
+// body returns the recorded body, redacted.
+func (r *bodyRecorder) body() Body {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.size == 0:
+		return Body{}
+	case r.size > maxBodySize:
+		return Body{Size: r.size, SHA256: hex.EncodeToString(r.hash.Sum(nil))}
+	case !utf8.Valid(r.buf.Bytes()):
+		return Body{Content: base64.StdEncoding.EncodeToString(r.buf.Bytes()), Encoding: "base64"}
+	}
+	return Body{Content: redactBody(r.buf.Bytes())}
+}
+
+// readBody reads a body, and returns its recorded form.
+func readBody(rd io.Reader) (Body, error) {
+	rec := newBodyRecorder()
+	if rd == nil {
+		return rec.body(), nil
+	}
+	_, err := io.Copy(rec, rd)
+	return rec.body(), err
+}
+
+// LoadCassette loads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	c := &Cassette{}
+	if err := yaml.Unmarshal(buf, c); err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette file. A Recorder appends interactions to its cassette file instead.
+func (c *Cassette) Save(path string) error {
+	buf, err := yaml.MarshalWithOptions(c, yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	if err := os.WriteFile(path, buf, 0o600); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+var (
+	redactedHeaders = []string{"Authorization", "Secretkey", "X-Amz-Security-Token", "Cookie", "Set-Cookie"}
+	redactedQuery   = []string{"X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token", "Signature", "AWSAccessKeyId"}
+	// redactedFields matches the string values of the fields holding secrets, e.g. SecretKey, AdminPassword or Kubeconfig
+	redactedFields = regexp.MustCompile(`(?i)("[A-Za-z]*(?:Secret|Password|PrivateKey|Kubeconfig)[A-Za-z]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range redactedHeaders {
+		if h.Get(name) != "" {
+			h.Set(name, redacted)
+		}
+	}
+	return h
+}
+
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for _, name := range redactedQuery {
+		if q.Has(name) {
+			q.Set(name, redacted)
+		}
+	}
+	ru := *u
+	ru.RawQuery = q.Encode()
+	return ru.String()
+}
+
+func redactBody(body []byte) string {
+	return redactedFields.ReplaceAllString(string(body), `$1"`+redacted+`"`)
+}
@@ -0,0 +1,167 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/outscale/octl/pkg/messages"
+)
+
+// Recorder records all HTTP exchanges into a cassette file.
+// It is used as a logger by the OUTSCALE SDK and as a transport by the AWS SDK.
+// Each exchange is appended to the cassette once its response has been read, as commands may exit at any time.
+// Bodies are recorded while they are read, so that large uploads and downloads are not buffered.
+type Recorder struct {
+	// Verbose also logs all exchanges, as VerboseLogger does.
+	Verbose bool
+
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewRecorder creates a recorder, writing to path.
+func NewRecorder(path string, verbose bool) *Recorder {
+	return &Recorder{path: path, Verbose: verbose}
+}
+
+func (r *Recorder) RequestHttp(ctx context.Context, req *http.Request) {
+	if r.Verbose {
+		VerboseLogger{}.RequestHttp(ctx, req)
+	}
+}
+
+func (r *Recorder) ResponseHttp(ctx context.Context, resp *http.Response, d time.Duration) {
+	if r.Verbose {
+		VerboseLogger{}.ResponseHttp(ctx, resp, d)
+	}
+	var body Body
+	if resp.Request != nil && resp.Request.GetBody != nil {
+		if bodyReader, err := resp.Request.GetBody(); err == nil {
+			body, _ = readBody(bodyReader)
+		}
+	}
+	r.record(resp.Request, func() Body { return body }, resp)
+}
+
+func (r *Recorder) Request(ctx context.Context, req any) {}
+
+func (r *Recorder) Response(ctx context.Context, resp any) {}
+
+func (r *Recorder) Error(ctx context.Context, err error) {}
+
+// Transport returns a transport, recording all exchanges sent to next.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return recordingTransport{recorder: r, next: next}
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := newBodyRecorder()
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &recordingBody{ReadCloser: req.Body, rec: body}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.recorder.record(req, body.body, resp)
+	return resp, nil
+}
+
+// recordingBody is a body recorded while it is read, calling done once it is read or closed.
+type recordingBody struct {
+	io.ReadCloser
+	rec  *bodyRecorder
+	done func()
+	once sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.rec.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *recordingBody) finish() {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+}
+
+// record records an exchange once the body of its response has been read, reqBody returning the body of its request.
+func (r *Recorder) record(req *http.Request, reqBody func() Body, resp *http.Response) {
+	body := &recordingBody{ReadCloser: resp.Body, rec: newBodyRecorder()}
+	body.done = func() {
+		i := Interaction{
+			Response: Response{
+				StatusCode: resp.StatusCode,
+				Header:     redactHeader(resp.Header),
+				Body:       body.rec.body(),
+			},
+		}
+		if req != nil {
+			i.Request = Request{
+				Method: req.Method,
+				URL:    redactURL(req.URL),
+				Header: redactHeader(req.Header),
+				Body:   reqBody(),
+			}
+		}
+		if err := r.append(i); err != nil {
+			messages.Warn("unable to record exchange: %v", err)
+		}
+	}
+	resp.Body = body
+}
+
+// append appends an interaction to the cassette file, creating it with the first interaction.
+func (r *Recorder) append(i Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		fd, err := os.OpenFile(r.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("write cassette: %w", err)
+		}
+		if _, err := fd.WriteString("interactions:\n"); err != nil {
+			_ = fd.Close()
+			return fmt.Errorf("write cassette: %w", err)
+		}
+		r.file = fd
+	}
+	// a sequence of a single interaction is an item of the sequence of interactions
+	buf, err := yaml.MarshalWithOptions([]Interaction{i}, yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	if _, err := r.file.Write(buf); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
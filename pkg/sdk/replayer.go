@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Replayer is a transport serving responses from a cassette, without network access.
+//
+// A request is matched to the first interaction not already replayed having the same method, path and query,
+// interactions also having the same body being preferred. Hosts are ignored, so a cassette can be replayed
+// using any profile.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// NewReplayer creates a replayer, serving the cassette stored at path.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{cassette: c, replayed: make([]bool, len(c.Interactions))}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	target := requestTarget(redactURL(req.URL))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := -1
+	for idx, i := range r.cassette.Interactions {
+		if r.replayed[idx] || i.Request.Method != req.Method || requestTarget(i.Request.URL) != target {
+			continue
+		}
+		if i.Request.Body == reqBody {
+			found = idx
+			break
+		}
+		if found < 0 {
+			found = idx
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, target)
+	}
+	r.replayed[found] = true
+	resp := r.cassette.Interactions[found].Response
+	body, size, err := resp.Body.reader()
+	if err != nil {
+		return nil, fmt.Errorf("recorded response for %s %s: %w", req.Method, target, err)
+	}
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	// bodies may have been changed by redaction
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(body),
+		ContentLength: size,
+		Request:       req,
+	}, nil
+}
+
+// requestTarget returns the path and query of an URL.
+func requestTarget(u string) string {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return pu.RequestURI()
+}
@@ -0,0 +1,19 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package spinner
+
+import "io"
+
+// NewTerminalProgress returns a progress refreshed in place on out, as on a terminal having height lines, without
+// refreshing it periodically.
+func NewTerminalProgress(out io.Writer, height int) *Progress {
+	return &Progress{out: out, tty: true, height: func() int { return height }}
+}
+
+// Refresh redraws the tasks, all tasks being drawn if final.
+func (p *Progress) Refresh(final bool) {
+	p.refresh(final)
+}
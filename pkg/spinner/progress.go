@@ -0,0 +1,193 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package spinner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-isatty"
+	"github.com/outscale/octl/pkg/style"
+)
+
+// State is the state of a task.
+type State int
+
+const (
+	Pending State = iota
+	Running
+	Succeeded
+	Failed
+)
+
+var frames = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}
+
+const refreshInterval = 100 * time.Millisecond
+
+// Progress displays the state of concurrent tasks, one line per task.
+// On a terminal, lines are refreshed in place, only running and failed tasks being displayed with a summary line if all
+// tasks do not fit in the terminal, and all tasks once stopped. Otherwise, a line is written each time a task ends.
+type Progress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	tty   bool
+	tasks []*Task
+	// height returns the number of lines of the terminal, 0 if unknown.
+	height func() int
+	// lines is the number of lines written by the last refresh.
+	lines int
+	frame int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Task is a task displayed by a Progress.
+type Task struct {
+	p      *Progress
+	title  string
+	state  State
+	detail string
+}
+
+// NewProgress starts displaying tasks on stderr, until Stop is called.
+func NewProgress() *Progress {
+	p := &Progress{
+		out: os.Stderr,
+		tty: isatty.IsTerminal(os.Stderr.Fd()),
+		height: func() int {
+			_, height, _ := term.GetSize(os.Stderr.Fd())
+			return height
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Add adds a pending task.
+func (p *Progress) Add(title string) *Task {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := &Task{p: p, title: title}
+	p.tasks = append(p.tasks, t)
+	return t
+}
+
+// Set sets the state of the task, detail being displayed next to its title.
+func (t *Task) Set(state State, detail string) {
+	t.p.mu.Lock()
+	defer t.p.mu.Unlock()
+	t.state = state
+	t.detail = detail
+	if !t.p.tty && (state == Succeeded || state == Failed) {
+		_, _ = fmt.Fprintln(t.p.out, t.line(""))
+	}
+}
+
+// Stop stops refreshing the display, leaving the final state of all tasks displayed.
+func (p *Progress) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *Progress) run() {
+	defer close(p.done)
+	if !p.tty {
+		<-p.stop
+		return
+	}
+	t := time.NewTicker(refreshInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			p.refresh(true)
+			return
+		case <-t.C:
+			p.refresh(false)
+		}
+	}
+}
+
+// refresh redraws the tasks in place, all tasks being drawn if final.
+func (p *Progress) refresh(final bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := strings.Builder{}
+	if p.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", p.lines)
+	}
+	// lines left by a longer previous refresh are cleared
+	b.WriteString("\r\x1b[J")
+	frame := frames[p.frame%len(frames)]
+	p.frame++
+	lines := p.visibleLines(final, frame)
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	p.lines = len(lines)
+	_, _ = io.WriteString(p.out, b.String())
+}
+
+// visibleLines returns the lines to draw. The cursor cannot move above the top of the terminal, so unless final, lines
+// are limited to the height of the terminal, only running and failed tasks being listed, followed by a summary line.
+func (p *Progress) visibleLines(final bool, frame string) []string {
+	height := p.height()
+	if final || height <= 0 || len(p.tasks) < height {
+		lines := make([]string, 0, len(p.tasks))
+		for _, t := range p.tasks {
+			lines = append(lines, t.line(frame))
+		}
+		return lines
+	}
+	var (
+		lines                 []string
+		pending, done, hidden int
+	)
+	for _, t := range p.tasks {
+		switch {
+		case t.state == Pending:
+			pending++
+		case t.state == Succeeded:
+			done++
+		case len(lines) < height-2:
+			lines = append(lines, t.line(frame))
+		default:
+			hidden++
+		}
+	}
+	summary := fmt.Sprintf("%d pending, %d done", pending, done)
+	if hidden > 0 {
+		summary += fmt.Sprintf(", %d more running or failed", hidden)
+	}
+	return append(lines, style.Faint.Render(summary))
+}
+
+func (t *Task) line(frame string) string {
+	var line string
+	switch t.state {
+	case Pending:
+		line = style.Faint.Render("· " + t.title)
+	case Running:
+		line = style.Yellow.Render(frame) + " " + style.Faint.Render(t.title)
+	case Succeeded:
+		line = style.Green.Render("✅ " + t.title)
+	case Failed:
+		line = style.Red.Render("❌ " + t.title)
+	}
+	if t.detail != "" {
+		line += " " + style.Faint.Render(t.detail)
+	}
+	return line
+}
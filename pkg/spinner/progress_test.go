@@ -0,0 +1,59 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package spinner_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/outscale/octl/pkg/spinner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := spinner.NewTerminalProgress(out, 10)
+	var tasks []*spinner.Task
+	for i := range 60 {
+		tasks = append(tasks, p.Add(fmt.Sprintf("task %d", i)))
+	}
+	for i, task := range tasks[:20] {
+		switch {
+		case i < 15:
+			task.Set(spinner.Succeeded, "")
+		case i == 15:
+			task.Set(spinner.Failed, "error")
+		default:
+			task.Set(spinner.Running, "")
+		}
+	}
+
+	t.Run("Lines are limited to the height of the terminal", func(t *testing.T) {
+		p.Refresh(false)
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		assert.Len(t, lines, 6)
+		assert.Contains(t, lines[0], "task 15")
+		assert.Contains(t, lines[5], "40 pending, 15 done")
+	})
+	t.Run("Running tasks not fitting in the terminal are counted", func(t *testing.T) {
+		for _, task := range tasks[20:30] {
+			task.Set(spinner.Running, "")
+		}
+		out.Reset()
+		p.Refresh(false)
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		assert.Len(t, lines, 9)
+		assert.Contains(t, lines[8], "30 pending, 15 done, 7 more running or failed")
+	})
+	t.Run("All tasks are displayed once stopped", func(t *testing.T) {
+		out.Reset()
+		p.Refresh(true)
+		assert.Equal(t, 60, strings.Count(out.String(), "\n"))
+		assert.Contains(t, out.String(), "task 59")
+	})
+}
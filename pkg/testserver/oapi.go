@@ -0,0 +1,396 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package testserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type resource = map[string]any
+
+type apiError struct {
+	status  int
+	code    string
+	typ     string
+	details string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%s): %s", e.typ, e.code, e.details)
+}
+
+func errMissingParameter(name string) error {
+	return &apiError{status: http.StatusBadRequest, code: "7000", typ: "MissingParameter", details: name + " is required"}
+}
+
+func errInvalidParameter(name string) error {
+	return &apiError{status: http.StatusBadRequest, code: "4047", typ: "InvalidParameterValue", details: "invalid value for " + name}
+}
+
+func errNotFound(id string) error {
+	return &apiError{status: http.StatusNotFound, code: "5071", typ: "InvalidResource", details: id + " not found"}
+}
+
+func errConflict(details string) error {
+	return &apiError{status: http.StatusConflict, code: "9073", typ: "ResourceConflict", details: details}
+}
+
+func errNotImplemented(call string) error {
+	return &apiError{status: http.StatusNotImplemented, code: "0", typ: "OperationNotSupported", details: call + " is not supported by the test server"}
+}
+
+func responseContext() map[string]any {
+	return map[string]any{"RequestId": uuid.NewString()}
+}
+
+func writeOAPIError(w http.ResponseWriter, err error) {
+	var aerr *apiError
+	if !errors.As(err, &aerr) {
+		aerr = &apiError{status: http.StatusInternalServerError, code: "0", typ: "InternalError", details: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(aerr.status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"Errors":          []map[string]string{{"Code": aerr.code, "Type": aerr.typ, "Details": aerr.details}},
+		"ResponseContext": responseContext(),
+	})
+}
+
+func (s *Server) serveOAPI(w http.ResponseWriter, r *http.Request, call string) {
+	if r.Method != http.MethodPost {
+		writeOAPIError(w, &apiError{status: http.StatusMethodNotAllowed, code: "0", typ: "InvalidAction", details: r.Method + " is not allowed"})
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeOAPIError(w, err)
+		return
+	}
+	req := map[string]any{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeOAPIError(w, &apiError{status: http.StatusBadRequest, code: "4019", typ: "InvalidParameter", details: err.Error()})
+			return
+		}
+	}
+	resp, err := s.call(call, req)
+	if err != nil {
+		writeOAPIError(w, err)
+		return
+	}
+	resp["ResponseContext"] = responseContext()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Call runs an OAPI call without going through HTTP, e.g. to seed resources before a test.
+// req and the response use the JSON representation of the call.
+func (s *Server) Call(call string, req map[string]any) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.call(call, req)
+}
+
+func (s *Server) call(call string, req map[string]any) (map[string]any, error) {
+	switch call {
+	case "CreateVms":
+		return s.createVms(req)
+	case "DeleteVms":
+		return s.setVmsState(req, "shutting-down")
+	case "StartVms":
+		return s.setVmsState(req, "pending")
+	case "StopVms":
+		return s.setVmsState(req, "stopping")
+	case "CreateTags":
+		return s.createTags(req)
+	case "DeleteTags":
+		return s.deleteTags(req)
+	case "ReadTags":
+		return s.readTags(req)
+	case "CreateSecurityGroupRule":
+		return s.createSecurityGroupRule(req)
+	case "DeleteSecurityGroupRule":
+		return s.deleteSecurityGroupRule(req)
+	case "ReadConsoleOutput":
+		return s.readConsoleOutput(req)
+	case "ReadAccounts":
+		return map[string]any{"Accounts": []any{map[string]any{"AccountId": AccountID}}}, nil
+	}
+	for _, k := range kinds {
+		switch call {
+		case "Create" + k.name:
+			return s.create(k, req)
+		case "Read" + k.list:
+			return s.read(k, req)
+		case "Update" + k.name:
+			return s.update(k, req)
+		case "Delete" + k.name:
+			return s.delete(k, req)
+		}
+	}
+	// listings of resources not handled by the server are always empty, other reads having no content
+	if list, found := strings.CutPrefix(call, "Read"); found {
+		if strings.HasSuffix(list, "s") {
+			return map[string]any{list: []any{}}, nil
+		}
+		return map[string]any{}, nil
+	}
+	return nil, errNotImplemented(call)
+}
+
+func (s *Server) create(k *kind, req map[string]any) (map[string]any, error) {
+	res := resource{k.id: newID(k.prefix), "Tags": []any{}}
+	if err := k.create(s, req, res); err != nil {
+		return nil, err
+	}
+	s.resources[k.name] = append(s.resources[k.name], res)
+	return map[string]any{k.name: res}, nil
+}
+
+func (s *Server) read(k *kind, req map[string]any) (map[string]any, error) {
+	s.advance(k)
+	filters, _ := req["Filters"].(map[string]any)
+	items := []any{}
+	for _, res := range s.resources[k.name] {
+		if matches(res, filters) {
+			items = append(items, res)
+		}
+	}
+	return s.page(k.list, items, req)
+}
+
+func (s *Server) update(k *kind, req map[string]any) (map[string]any, error) {
+	res, err := s.find(k, req)
+	if err != nil {
+		return nil, err
+	}
+	// only known attributes are updated
+	for key, value := range req {
+		if _, found := res[key]; found && key != k.id {
+			res[key] = value
+		}
+	}
+	return map[string]any{k.name: res}, nil
+}
+
+func (s *Server) delete(k *kind, req map[string]any) (map[string]any, error) {
+	res, err := s.find(k, req)
+	if err != nil {
+		return nil, err
+	}
+	if k.delete != nil {
+		if err := k.delete(s, res); err != nil {
+			return nil, err
+		}
+	}
+	s.remove(k, res[k.id])
+	return map[string]any{}, nil
+}
+
+func (s *Server) find(k *kind, req map[string]any) (resource, error) {
+	id, ok := req[k.id].(string)
+	if !ok || id == "" {
+		return nil, errMissingParameter(k.id)
+	}
+	res, found := s.get(k, id)
+	if !found {
+		return nil, errNotFound(id)
+	}
+	return res, nil
+}
+
+func (s *Server) get(k *kind, id string) (resource, bool) {
+	for _, res := range s.resources[k.name] {
+		if res[k.id] == id {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) remove(k *kind, id any) {
+	s.resources[k.name] = slices.DeleteFunc(s.resources[k.name], func(res resource) bool {
+		return res[k.id] == id
+	})
+}
+
+// states lists the automatic state transitions, applied each time resources are read.
+var states = map[string]string{
+	"pending":       "running",
+	"stopping":      "stopped",
+	"shutting-down": "terminated",
+	"creating":      "available",
+}
+
+func (s *Server) advance(k *kind) {
+	// terminated resources are reported once
+	s.resources[k.name] = slices.DeleteFunc(s.resources[k.name], func(res resource) bool {
+		return res["State"] == "terminated"
+	})
+	for _, res := range s.resources[k.name] {
+		state, _ := res["State"].(string)
+		if next, found := states[state]; found {
+			res["State"] = next
+		}
+	}
+}
+
+// page returns the page of items requested using ResultsPerPage and NextPageToken.
+func (s *Server) page(list string, items []any, req map[string]any) (map[string]any, error) {
+	size := s.pageSize
+	if rpp, ok := req["ResultsPerPage"].(float64); ok {
+		size = int(rpp)
+	}
+	start := 0
+	if token, ok := req["NextPageToken"].(string); ok && token != "" {
+		buf, err := base64.StdEncoding.DecodeString(token)
+		if err == nil {
+			start, err = strconv.Atoi(string(buf))
+		}
+		if err != nil || start > len(items) {
+			return nil, errInvalidParameter("NextPageToken")
+		}
+	}
+	end := len(items)
+	if size > 0 {
+		end = min(start+size, len(items))
+	}
+	resp := map[string]any{list: items[start:end]}
+	if end < len(items) {
+		resp["NextPageToken"] = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return resp, nil
+}
+
+// filterAttributes maps filters not named after the plural of the attribute they apply to.
+var filterAttributes = map[string]string{
+	"VmStateNames": "State",
+	"VolumeStates": "State",
+	"VolumeSizes":  "Size",
+}
+
+// matches checks if a resource matches all filters.
+// Filters apply to the attribute named after their singular form (NetIds => NetId), either at the top level
+// of the resource, or within a list of objects (SecurityGroupIds => SecurityGroups[].SecurityGroupId).
+func matches(res resource, filters map[string]any) bool {
+	for name, filter := range filters {
+		var wanted []string
+		switch filter := filter.(type) {
+		case []any:
+			wanted = strs(filter)
+		default:
+			wanted = []string{fmt.Sprint(filter)}
+		}
+		var values []string
+		switch name {
+		case "TagKeys", "TagValues", "Tags":
+			for _, tag := range tagsOf(res) {
+				switch name {
+				case "TagKeys":
+					values = append(values, tag.key)
+				case "TagValues":
+					values = append(values, tag.value)
+				default:
+					values = append(values, tag.key+"="+tag.value)
+				}
+			}
+		default:
+			attr, found := filterAttributes[name]
+			if !found {
+				attr = strings.TrimSuffix(name, "s")
+			}
+			values = attribute(res, attr)
+		}
+		if !slices.ContainsFunc(values, func(v string) bool { return slices.Contains(wanted, v) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func attribute(res resource, attr string) []string {
+	if v, found := res[attr]; found {
+		return []string{fmt.Sprint(v)}
+	}
+	var values []string
+	for _, v := range res {
+		list, ok := v.([]any)
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			if obj, ok := item.(map[string]any); ok {
+				if v, found := obj[attr]; found {
+					values = append(values, fmt.Sprint(v))
+				}
+			}
+		}
+	}
+	return values
+}
+
+func strs(list []any) []string {
+	res := make([]string, 0, len(list))
+	for _, v := range list {
+		res = append(res, fmt.Sprint(v))
+	}
+	return res
+}
+
+func newID(prefix string) string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return prefix + "-" + hex.EncodeToString(buf)
+}
+
+func now() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func stringParam(req map[string]any, name string, required bool) (string, error) {
+	v, found := req[name]
+	if !found || v == "" {
+		if required {
+			return "", errMissingParameter(name)
+		}
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errInvalidParameter(name)
+	}
+	return s, nil
+}
+
+func stringsParam(req map[string]any, name string, required bool) ([]string, error) {
+	v, found := req[name]
+	if !found {
+		if required {
+			return nil, errMissingParameter(name)
+		}
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, errInvalidParameter(name)
+	}
+	if required && len(list) == 0 {
+		return nil, errMissingParameter(name)
+	}
+	return strs(list), nil
+}
@@ -0,0 +1,427 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package testserver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type kind struct {
+	// name is the name of the resource in calls, e.g. Net for CreateNet.
+	name string
+	// list is the name of the resource in listings, e.g. Nets for ReadNets.
+	list string
+	// id is the attribute storing the resource ID.
+	id     string
+	prefix string
+	// tagType is the resource type reported by ReadTags.
+	tagType string
+
+	create func(s *Server, req map[string]any, res resource) error
+	// delete checks if a resource can be deleted, and cleans its dependencies.
+	delete func(s *Server, res resource) error
+}
+
+var (
+	nets           = &kind{name: "Net", list: "Nets", id: "NetId", prefix: "vpc", tagType: "vpc"}
+	subnets        = &kind{name: "Subnet", list: "Subnets", id: "SubnetId", prefix: "subnet", tagType: "subnet"}
+	securityGroups = &kind{name: "SecurityGroup", list: "SecurityGroups", id: "SecurityGroupId", prefix: "sg", tagType: "security-group"}
+	volumes        = &kind{name: "Volume", list: "Volumes", id: "VolumeId", prefix: "vol", tagType: "volume"}
+	vms            = &kind{name: "Vm", list: "Vms", id: "VmId", prefix: "i", tagType: "instance"}
+
+	kinds = []*kind{nets, subnets, securityGroups, volumes, vms}
+)
+
+// handlers reference kinds, they cannot be set at declaration.
+func init() {
+	nets.create, nets.delete = createNet, deleteNet
+	subnets.create, subnets.delete = createSubnet, deleteSubnet
+	securityGroups.create, securityGroups.delete = createSecurityGroup, deleteSecurityGroup
+	volumes.create, volumes.delete = createVolume, deleteVolume
+	// VMs are created by CreateVms
+	vms.create = func(*Server, map[string]any, resource) error { return errNotImplemented("CreateVm") }
+}
+
+func createNet(s *Server, req map[string]any, res resource) error {
+	ipRange, err := stringParam(req, "IpRange", true)
+	if err != nil {
+		return err
+	}
+	tenancy, err := stringParam(req, "Tenancy", false)
+	if err != nil {
+		return err
+	}
+	if tenancy == "" {
+		tenancy = "default"
+	}
+	res["IpRange"] = ipRange
+	res["Tenancy"] = tenancy
+	res["State"] = "available"
+	res["DhcpOptionsSetId"] = newID("dopt")
+	// each net has its own default security group
+	s.resources[securityGroups.name] = append(s.resources[securityGroups.name], resource{
+		"SecurityGroupId":   newID(securityGroups.prefix),
+		"SecurityGroupName": "default",
+		"Description":       "default security group",
+		"NetId":             res["NetId"],
+		"AccountId":         AccountID,
+		"InboundRules":      []any{},
+		"OutboundRules":     []any{allTraffic()},
+		"Tags":              []any{},
+	})
+	return nil
+}
+
+func deleteNet(s *Server, res resource) error {
+	id := res["NetId"]
+	if slices.ContainsFunc(s.resources[subnets.name], func(sn resource) bool { return sn["NetId"] == id }) {
+		return errConflict(fmt.Sprintf("%s has subnets", id))
+	}
+	if slices.ContainsFunc(s.resources[securityGroups.name], func(sg resource) bool {
+		return sg["NetId"] == id && sg["SecurityGroupName"] != "default"
+	}) {
+		return errConflict(fmt.Sprintf("%s has security groups", id))
+	}
+	s.resources[securityGroups.name] = slices.DeleteFunc(s.resources[securityGroups.name], func(sg resource) bool {
+		return sg["NetId"] == id
+	})
+	return nil
+}
+
+func createSubnet(s *Server, req map[string]any, res resource) error {
+	netID, err := stringParam(req, "NetId", true)
+	if err != nil {
+		return err
+	}
+	if _, found := s.get(nets, netID); !found {
+		return errNotFound(netID)
+	}
+	ipRange, err := stringParam(req, "IpRange", true)
+	if err != nil {
+		return err
+	}
+	subregion, err := stringParam(req, "SubregionName", false)
+	if err != nil {
+		return err
+	}
+	if subregion == "" {
+		subregion = Region + "a"
+	}
+	res["NetId"] = netID
+	res["IpRange"] = ipRange
+	res["SubregionName"] = subregion
+	res["State"] = "available"
+	res["AvailableIpsCount"] = 251
+	res["MapPublicIpOnLaunch"] = false
+	return nil
+}
+
+func deleteSubnet(s *Server, res resource) error {
+	if slices.ContainsFunc(s.resources[vms.name], func(vm resource) bool { return alive(vm) && vm["SubnetId"] == res["SubnetId"] }) {
+		return errConflict(fmt.Sprintf("%s has VMs", res["SubnetId"]))
+	}
+	return nil
+}
+
+func createSecurityGroup(s *Server, req map[string]any, res resource) error {
+	name, err := stringParam(req, "SecurityGroupName", true)
+	if err != nil {
+		return err
+	}
+	description, err := stringParam(req, "Description", true)
+	if err != nil {
+		return err
+	}
+	netID, err := stringParam(req, "NetId", false)
+	if err != nil {
+		return err
+	}
+	if netID != "" {
+		if _, found := s.get(nets, netID); !found {
+			return errNotFound(netID)
+		}
+	}
+	if slices.ContainsFunc(s.resources[securityGroups.name], func(sg resource) bool {
+		return sg["SecurityGroupName"] == name && (sg["NetId"] == netID || sg["NetId"] == nil && netID == "")
+	}) {
+		return errConflict(fmt.Sprintf("security group %s already exists", name))
+	}
+	res["SecurityGroupName"] = name
+	res["Description"] = description
+	res["AccountId"] = AccountID
+	res["InboundRules"] = []any{}
+	res["OutboundRules"] = []any{}
+	if netID != "" {
+		res["NetId"] = netID
+		res["OutboundRules"] = []any{allTraffic()}
+	}
+	return nil
+}
+
+// createSecurityGroupRule adds rules to a security group, either set by Rules or by the single rule parameters.
+// securityGroupRules returns the security group, the flow and the rules of a rule creation or deletion.
+func (s *Server) securityGroupRules(req map[string]any) (resource, string, []any, error) {
+	sg, err := s.find(securityGroups, req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	flow, err := stringParam(req, "Flow", true)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if flow != "Inbound" && flow != "Outbound" {
+		return nil, "", nil, errInvalidParameter("Flow")
+	}
+	rules, _ := req["Rules"].([]any)
+	if len(rules) == 0 {
+		rule := map[string]any{"FromPortRange": req["FromPortRange"], "ToPortRange": req["ToPortRange"], "IpProtocol": req["IpProtocol"]}
+		if ipRange, _ := stringParam(req, "IpRange", false); ipRange != "" {
+			rule["IpRanges"] = []any{ipRange}
+		}
+		rules = []any{rule}
+	}
+	return sg, flow, rules, nil
+}
+
+func (s *Server) createSecurityGroupRule(req map[string]any) (map[string]any, error) {
+	sg, flow, rules, err := s.securityGroupRules(req)
+	if err != nil {
+		return nil, err
+	}
+	list, _ := sg[flow+"Rules"].([]any)
+	sg[flow+"Rules"] = append(list, rules...)
+	return map[string]any{securityGroups.name: sg}, nil
+}
+
+// deleteSecurityGroupRule removes the sources of the requested rules from the rules having the same protocol and ports,
+// rules left without source being deleted.
+func (s *Server) deleteSecurityGroupRule(req map[string]any) (map[string]any, error) {
+	sg, flow, rules, err := s.securityGroupRules(req)
+	if err != nil {
+		return nil, err
+	}
+	sameRule := func(a, b map[string]any) bool {
+		return fmt.Sprint(a["IpProtocol"], a["FromPortRange"], a["ToPortRange"]) == fmt.Sprint(b["IpProtocol"], b["FromPortRange"], b["ToPortRange"])
+	}
+	member := func(v any) string {
+		m, _ := v.(map[string]any)
+		if id, _ := m["SecurityGroupId"].(string); id != "" {
+			return id
+		}
+		return fmt.Sprint(m["SecurityGroupName"])
+	}
+	list, _ := sg[flow+"Rules"].([]any)
+	kept := []any{}
+	for _, e := range list {
+		e, _ := e.(map[string]any)
+		ipRanges, _ := e["IpRanges"].([]any)
+		members, _ := e["SecurityGroupsMembers"].([]any)
+		for _, r := range rules {
+			r, _ := r.(map[string]any)
+			if !sameRule(e, r) {
+				continue
+			}
+			removed, _ := r["IpRanges"].([]any)
+			ipRanges = slices.DeleteFunc(slices.Clone(ipRanges), func(ipr any) bool { return slices.Contains(removed, ipr) })
+			removedMembers, _ := r["SecurityGroupsMembers"].([]any)
+			members = slices.DeleteFunc(slices.Clone(members), func(m any) bool {
+				return slices.ContainsFunc(removedMembers, func(rm any) bool { return member(rm) == member(m) })
+			})
+		}
+		if len(ipRanges) == 0 && len(members) == 0 {
+			continue
+		}
+		e["IpRanges"], e["SecurityGroupsMembers"] = ipRanges, members
+		kept = append(kept, e)
+	}
+	sg[flow+"Rules"] = kept
+	return map[string]any{securityGroups.name: sg}, nil
+}
+
+func deleteSecurityGroup(s *Server, res resource) error {
+	if res["SecurityGroupName"] == "default" && res["NetId"] != nil {
+		return errConflict("the default security group of a net cannot be deleted")
+	}
+	if slices.ContainsFunc(s.resources[vms.name], func(vm resource) bool {
+		return alive(vm) && slices.Contains(attribute(vm, "SecurityGroupId"), fmt.Sprint(res["SecurityGroupId"]))
+	}) {
+		return errConflict(fmt.Sprintf("%s is used by VMs", res["SecurityGroupId"]))
+	}
+	return nil
+}
+
+func allTraffic() map[string]any {
+	return map[string]any{
+		"FromPortRange": -1,
+		"ToPortRange":   -1,
+		"IpProtocol":    "-1",
+		"IpRanges":      []any{"0.0.0.0/0"},
+	}
+}
+
+func createVolume(s *Server, req map[string]any, res resource) error {
+	subregion, err := stringParam(req, "SubregionName", true)
+	if err != nil {
+		return err
+	}
+	typ, err := stringParam(req, "VolumeType", false)
+	if err != nil {
+		return err
+	}
+	if typ == "" {
+		typ = "standard"
+	}
+	size, ok := req["Size"].(float64)
+	if !ok {
+		return errMissingParameter("Size")
+	}
+	res["SubregionName"] = subregion
+	res["VolumeType"] = typ
+	res["Size"] = int(size)
+	if iops, ok := req["Iops"].(float64); ok {
+		res["Iops"] = int(iops)
+	}
+	res["State"] = "creating"
+	res["CreationDate"] = now()
+	res["LinkedVolumes"] = []any{}
+	return nil
+}
+
+func deleteVolume(s *Server, res resource) error {
+	if linked, _ := res["LinkedVolumes"].([]any); len(linked) > 0 {
+		return errConflict(fmt.Sprintf("%s is linked", res["VolumeId"]))
+	}
+	return nil
+}
+
+func alive(vm resource) bool {
+	return vm["State"] != "shutting-down" && vm["State"] != "terminated"
+}
+
+func (s *Server) createVms(req map[string]any) (map[string]any, error) {
+	imageID, err := stringParam(req, "ImageId", true)
+	if err != nil {
+		return nil, err
+	}
+	vmType, err := stringParam(req, "VmType", false)
+	if err != nil {
+		return nil, err
+	}
+	if vmType == "" {
+		vmType = "tinav5.c1r1p2"
+	}
+	keypair, err := stringParam(req, "KeypairName", false)
+	if err != nil {
+		return nil, err
+	}
+	subregion := Region + "a"
+	if placement, ok := req["Placement"].(map[string]any); ok {
+		if sr, ok := placement["SubregionName"].(string); ok && sr != "" {
+			subregion = sr
+		}
+	}
+	subnetID, err := stringParam(req, "SubnetId", false)
+	if err != nil {
+		return nil, err
+	}
+	var netID any
+	if subnetID != "" {
+		subnet, found := s.get(subnets, subnetID)
+		if !found {
+			return nil, errNotFound(subnetID)
+		}
+		netID = subnet["NetId"]
+		subregion, _ = subnet["SubregionName"].(string)
+	}
+	sgIDs, err := stringsParam(req, "SecurityGroupIds", false)
+	if err != nil {
+		return nil, err
+	}
+	sgs := []any{}
+	for _, id := range sgIDs {
+		sg, found := s.get(securityGroups, id)
+		if !found {
+			return nil, errNotFound(id)
+		}
+		sgs = append(sgs, map[string]any{"SecurityGroupId": id, "SecurityGroupName": sg["SecurityGroupName"]})
+	}
+	if len(sgs) == 0 && netID != nil {
+		for _, sg := range s.resources[securityGroups.name] {
+			if sg["NetId"] == netID && sg["SecurityGroupName"] == "default" {
+				sgs = append(sgs, map[string]any{"SecurityGroupId": sg["SecurityGroupId"], "SecurityGroupName": "default"})
+			}
+		}
+	}
+	count := 1
+	if c, ok := req["MaxVmsCount"].(float64); ok && c > 0 {
+		count = int(c)
+	}
+	created := []any{}
+	for range count {
+		vm := resource{
+			"VmId":           newID(vms.prefix),
+			"ImageId":        imageID,
+			"VmType":         vmType,
+			"State":          "pending",
+			"Placement":      map[string]any{"SubregionName": subregion, "Tenancy": "default"},
+			"SecurityGroups": sgs,
+			"CreationDate":   now(),
+			"Tags":           []any{},
+		}
+		if keypair != "" {
+			vm["KeypairName"] = keypair
+		}
+		if subnetID != "" {
+			vm["SubnetId"] = subnetID
+			vm["NetId"] = netID
+			vm["PrivateIp"] = fmt.Sprintf("10.0.0.%d", 10+len(s.resources[vms.name]))
+		}
+		s.resources[vms.name] = append(s.resources[vms.name], vm)
+		created = append(created, vm)
+	}
+	return map[string]any{"Vms": created}, nil
+}
+
+func (s *Server) setVmsState(req map[string]any, state string) (map[string]any, error) {
+	ids, err := stringsParam(req, "VmIds", true)
+	if err != nil {
+		return nil, err
+	}
+	var found []resource
+	for _, id := range ids {
+		vm, ok := s.get(vms, id)
+		if !ok || !alive(vm) {
+			return nil, errNotFound(id)
+		}
+		found = append(found, vm)
+	}
+	changes := []any{}
+	for _, vm := range found {
+		changes = append(changes, map[string]any{"VmId": vm["VmId"], "PreviousState": vm["State"], "CurrentState": state})
+		vm["State"] = state
+	}
+	return map[string]any{"Vms": changes}, nil
+}
+
+// readConsoleOutput returns a boot log growing by one line at each call, cloud-init finishing at the third call.
+func (s *Server) readConsoleOutput(req map[string]any) (map[string]any, error) {
+	vm, err := s.find(vms, req)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprint(vm["VmId"])
+	s.consoles[id]++
+	var log strings.Builder
+	for i := 1; i <= s.consoles[id]; i++ {
+		fmt.Fprintf(&log, "[%4d.000000] boot step %d\n", i, i)
+	}
+	if s.consoles[id] >= 3 {
+		log.WriteString("Cloud-init v. 24.1 finished\n")
+	}
+	return map[string]any{"VmId": id, "ConsoleOutput": base64.StdEncoding.EncodeToString([]byte(log.String()))}, nil
+}
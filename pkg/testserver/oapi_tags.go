@@ -0,0 +1,121 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package testserver
+
+import (
+	"slices"
+)
+
+type tag struct {
+	key, value string
+}
+
+func tagsOf(res resource) []tag {
+	list, _ := res["Tags"].([]any)
+	tags := make([]tag, 0, len(list))
+	for _, t := range list {
+		t, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		key, _ := t["Key"].(string)
+		value, _ := t["Value"].(string)
+		tags = append(tags, tag{key: key, value: value})
+	}
+	return tags
+}
+
+func setTags(res resource, tags []tag) {
+	list := make([]any, 0, len(tags))
+	for _, t := range tags {
+		list = append(list, map[string]any{"Key": t.key, "Value": t.value})
+	}
+	res["Tags"] = list
+}
+
+// findAny finds a resource of any kind by its ID.
+func (s *Server) findAny(id string) (*kind, resource, bool) {
+	for _, k := range kinds {
+		if res, found := s.get(k, id); found {
+			return k, res, true
+		}
+	}
+	return nil, nil, false
+}
+
+func tagsParam(req map[string]any) ([]string, []tag, error) {
+	ids, err := stringsParam(req, "ResourceIds", true)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, ok := req["Tags"].([]any)
+	if !ok || len(list) == 0 {
+		return nil, nil, errMissingParameter("Tags")
+	}
+	tags := tagsOf(resource{"Tags": list})
+	return ids, tags, nil
+}
+
+func (s *Server) createTags(req map[string]any) (map[string]any, error) {
+	ids, tags, err := tagsParam(req)
+	if err != nil {
+		return nil, err
+	}
+	var found []resource
+	for _, id := range ids {
+		_, res, ok := s.findAny(id)
+		if !ok {
+			return nil, errNotFound(id)
+		}
+		found = append(found, res)
+	}
+	for _, res := range found {
+		current := tagsOf(res)
+		for _, t := range tags {
+			idx := slices.IndexFunc(current, func(c tag) bool { return c.key == t.key })
+			if idx >= 0 {
+				current[idx] = t
+			} else {
+				current = append(current, t)
+			}
+		}
+		setTags(res, current)
+	}
+	return map[string]any{}, nil
+}
+
+func (s *Server) deleteTags(req map[string]any) (map[string]any, error) {
+	ids, tags, err := tagsParam(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		_, res, ok := s.findAny(id)
+		if !ok {
+			return nil, errNotFound(id)
+		}
+		// a tag is deleted if its key matches, and its value if one is set
+		setTags(res, slices.DeleteFunc(tagsOf(res), func(c tag) bool {
+			return slices.ContainsFunc(tags, func(t tag) bool { return t.key == c.key && (t.value == "" || t.value == c.value) })
+		}))
+	}
+	return map[string]any{}, nil
+}
+
+func (s *Server) readTags(req map[string]any) (map[string]any, error) {
+	filters, _ := req["Filters"].(map[string]any)
+	items := []any{}
+	for _, k := range kinds {
+		for _, res := range s.resources[k.name] {
+			for _, t := range tagsOf(res) {
+				item := resource{"ResourceType": k.tagType, "ResourceId": res[k.id], "Key": t.key, "Value": t.value}
+				if matches(item, filters) {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+	return s.page("Tags", items, req)
+}
@@ -0,0 +1,593 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package testserver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+type bucket struct {
+	created time.Time
+	objects map[string]*object
+	uploads map[string]*upload
+}
+
+// upload is an incomplete multipart upload.
+type upload struct {
+	key         string
+	initiated   time.Time
+	contentType string
+	parts       map[int]*object
+}
+
+type object struct {
+	body         []byte
+	etag         string
+	contentType  string
+	metadata     map[string]string
+	lastModified time.Time
+}
+
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *s3Error) Error() string {
+	return e.code + ": " + e.message
+}
+
+func errNoSuchBucket(name string) error {
+	return &s3Error{status: http.StatusNotFound, code: "NoSuchBucket", message: "bucket " + name + " does not exist"}
+}
+
+func errNoSuchKey(key string) error {
+	return &s3Error{status: http.StatusNotFound, code: "NoSuchKey", message: "key " + key + " does not exist"}
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, err error) {
+	var serr *s3Error
+	if !errors.As(err, &serr) {
+		serr = &s3Error{status: http.StatusInternalServerError, code: "InternalError", message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(serr.status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string
+		Message   string
+		RequestID string `xml:"RequestId"`
+	}{Code: serr.code, Message: serr.message, RequestID: uuid.NewString()})
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+// serveOOS serves a subset of the S3 API, using path-style addressing.
+func (s *Server) serveOOS(w http.ResponseWriter, r *http.Request) {
+	name, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	q := r.URL.Query()
+	// the operation name is sent by some SDKs, it is not a subresource
+	q.Del("x-id")
+	// the signature of presigned URLs is sent in the query
+	for param := range q {
+		if strings.HasPrefix(param, "X-Amz-") {
+			q.Del(param)
+		}
+	}
+	var err error
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		s.listBuckets(w)
+	case key == "" && r.Method == http.MethodPut:
+		err = s.createBucket(name)
+	case key == "" && r.Method == http.MethodDelete:
+		err = s.deleteBucket(w, name)
+	case key == "" && r.Method == http.MethodHead:
+		_, err = s.bucket(name)
+	case key == "" && r.Method == http.MethodPost && q.Has("delete"):
+		err = s.deleteObjects(w, r, name)
+	case key == "" && r.Method == http.MethodGet && (len(q) == 0 || q.Has("list-type")):
+		err = s.listObjects(w, r, name)
+	case key == "" && r.Method == http.MethodGet && q.Has("uploads"):
+		err = s.listMultipartUploads(w, name)
+	case key != "" && r.Method == http.MethodPost && q.Has("uploads"):
+		err = s.createMultipartUpload(w, r, name, key)
+	case key != "" && r.Method == http.MethodPut && q.Has("uploadId") && q.Has("partNumber"):
+		err = s.uploadPart(w, r, name, q.Get("uploadId"), q.Get("partNumber"))
+	case key != "" && r.Method == http.MethodPost && q.Has("uploadId"):
+		err = s.completeMultipartUpload(w, r, name, key, q.Get("uploadId"))
+	case key != "" && r.Method == http.MethodDelete && q.Has("uploadId"):
+		err = s.abortMultipartUpload(w, name, q.Get("uploadId"))
+	case key != "" && r.Method == http.MethodPut && len(q) == 0 && r.Header.Get("X-Amz-Copy-Source") != "":
+		err = s.copyObject(w, r, name, key)
+	case key != "" && r.Method == http.MethodPut && len(q) == 0:
+		err = s.putObject(w, r, name, key)
+	case key != "" && (r.Method == http.MethodGet || r.Method == http.MethodHead) && len(q) == 0:
+		err = s.getObject(w, r, name, key)
+	case key != "" && r.Method == http.MethodDelete && len(q) == 0:
+		err = s.deleteObject(w, name, key)
+	default:
+		err = &s3Error{status: http.StatusNotImplemented, code: "NotImplemented", message: r.Method + " " + r.URL.String() + " is not supported by the test server"}
+	}
+	if err != nil {
+		writeS3Error(w, r, err)
+	}
+}
+
+func (s *Server) bucket(name string) (*bucket, error) {
+	b, found := s.buckets[name]
+	if !found {
+		return nil, errNoSuchBucket(name)
+	}
+	return b, nil
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type bucket struct {
+		Name         string
+		CreationDate string
+	}
+	names := lo.Keys(s.buckets)
+	slices.Sort(names)
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Owner   struct{ ID string }
+		Buckets []bucket `xml:"Buckets>Bucket"`
+	}{
+		Xmlns: s3Namespace,
+		Owner: struct{ ID string }{ID: AccountID},
+		Buckets: lo.Map(names, func(name string, _ int) bucket {
+			return bucket{Name: name, CreationDate: s.buckets[name].created.Format(time.RFC3339)}
+		}),
+	})
+}
+
+func (s *Server) createBucket(name string) error {
+	if _, found := s.buckets[name]; found {
+		return &s3Error{status: http.StatusConflict, code: "BucketAlreadyOwnedByYou", message: "bucket " + name + " already exists"}
+	}
+	s.buckets[name] = &bucket{created: time.Now().UTC(), objects: map[string]*object{}, uploads: map[string]*upload{}}
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, name string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	if len(b.objects) > 0 {
+		return &s3Error{status: http.StatusConflict, code: "BucketNotEmpty", message: "bucket " + name + " is not empty"}
+	}
+	delete(s.buckets, name)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type listEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, name string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	q := r.URL.Query()
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	maxKeys := 1000
+	if s.pageSize > 0 {
+		maxKeys = s.pageSize
+	}
+	if mk := q.Get("max-keys"); mk != "" {
+		maxKeys, err = strconv.Atoi(mk)
+		if err != nil {
+			return &s3Error{status: http.StatusBadRequest, code: "InvalidArgument", message: "invalid max-keys"}
+		}
+	}
+	after := q.Get("start-after")
+	if token := q.Get("continuation-token"); token != "" {
+		after = token
+	}
+
+	keys := lo.Keys(b.objects)
+	slices.Sort(keys)
+	var (
+		contents []listEntry
+		prefixes []string
+		next     string
+	)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+		var common string
+		if idx := strings.Index(key[len(prefix):], delimiter); delimiter != "" && idx >= 0 {
+			common = key[:len(prefix)+idx+len(delimiter)]
+		}
+		if common != "" && slices.Contains(prefixes, common) {
+			after = key
+			continue
+		}
+		if len(contents)+len(prefixes) >= maxKeys {
+			next = after
+			break
+		}
+		if common != "" {
+			prefixes = append(prefixes, common)
+			after = key
+			continue
+		}
+		obj := b.objects[key]
+		contents = append(contents, listEntry{
+			Key:          key,
+			LastModified: obj.lastModified.Format("2006-01-02T15:04:05.000Z"),
+			ETag:         obj.etag,
+			Size:         len(obj.body),
+			StorageClass: "STANDARD",
+		})
+		after = key
+	}
+	type commonPrefix struct{ Prefix string }
+	writeXML(w, struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Xmlns                 string   `xml:"xmlns,attr"`
+		Name                  string
+		Prefix                string
+		Delimiter             string `xml:",omitempty"`
+		MaxKeys               int
+		KeyCount              int
+		IsTruncated           bool
+		ContinuationToken     string         `xml:",omitempty"`
+		NextContinuationToken string         `xml:",omitempty"`
+		Contents              []listEntry    `xml:"Contents"`
+		CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+	}{
+		Xmlns:                 s3Namespace,
+		Name:                  name,
+		Prefix:                prefix,
+		Delimiter:             delimiter,
+		MaxKeys:               maxKeys,
+		KeyCount:              len(contents) + len(prefixes),
+		IsTruncated:           next != "",
+		ContinuationToken:     q.Get("continuation-token"),
+		NextContinuationToken: next,
+		Contents:              contents,
+		CommonPrefixes:        lo.Map(prefixes, func(p string, _ int) commonPrefix { return commonPrefix{Prefix: p} }),
+	})
+	return nil
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, name, key string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "IncompleteBody", message: err.Error()}
+	}
+	obj := newObject(body)
+	obj.contentType = r.Header.Get("Content-Type")
+	for h := range r.Header {
+		if meta, found := strings.CutPrefix(strings.ToLower(h), "x-amz-meta-"); found {
+			obj.metadata[meta] = r.Header.Get(h)
+		}
+	}
+	b.objects[key] = obj
+	w.Header().Set("ETag", obj.etag)
+	return nil
+}
+
+func newObject(body []byte) *object {
+	sum := md5.Sum(body) //nolint:gosec
+	return &object{
+		body:         body,
+		etag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		metadata:     map[string]string{},
+		lastModified: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// readBody reads the body of a request, decoding it if it has been sent using the aws-chunked encoding.
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	br := bufio.NewReader(r.Body)
+	body := &bytes.Buffer{}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		hexSize, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(hexSize, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			// trailers are ignored
+			return body.Bytes(), nil
+		}
+		if _, err := io.CopyN(body, br, size); err != nil {
+			return nil, err
+		}
+		if _, err := br.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, name, key string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	obj, found := b.objects[key]
+	if !found {
+		return errNoSuchKey(key)
+	}
+	contentType := obj.contentType
+	if contentType == "" {
+		contentType = "binary/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.body)))
+	for k, v := range obj.metadata {
+		w.Header().Set("X-Amz-Meta-"+k, v)
+	}
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, _ = w.Write(obj.body)
+	return nil
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, name, key string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "InvalidArgument", message: "invalid copy source"}
+	}
+	source, _, _ = strings.Cut(source, "?")
+	srcName, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	src, err := s.bucket(srcName)
+	if err != nil {
+		return err
+	}
+	srcObj, found := src.objects[srcKey]
+	if !found {
+		return errNoSuchKey(srcKey)
+	}
+	obj := newObject(srcObj.body)
+	obj.contentType = srcObj.contentType
+	for k, v := range srcObj.metadata {
+		obj.metadata[k] = v
+	}
+	b.objects[key] = obj
+	writeXML(w, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string
+		LastModified string
+	}{ETag: obj.etag, LastModified: obj.lastModified.Format("2006-01-02T15:04:05.000Z")})
+	return nil
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, name, key string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	delete(b.objects, key)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, name string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "IncompleteBody", message: err.Error()}
+	}
+	var req struct {
+		Objects []struct{ Key string } `xml:"Object"`
+		Quiet   bool
+	}
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "MalformedXML", message: err.Error()}
+	}
+	type deleted struct{ Key string }
+	var resp []deleted
+	for _, o := range req.Objects {
+		delete(b.objects, o.Key)
+		if !req.Quiet {
+			resp = append(resp, deleted{Key: o.Key})
+		}
+	}
+	writeXML(w, struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Xmlns   string    `xml:"xmlns,attr"`
+		Deleted []deleted `xml:"Deleted"`
+	}{Xmlns: s3Namespace, Deleted: resp})
+	return nil
+}
+
+func errNoSuchUpload(id string) error {
+	return &s3Error{status: http.StatusNotFound, code: "NoSuchUpload", message: "upload " + id + " does not exist"}
+}
+
+func (s *Server) upload(name, id string) (*bucket, *upload, error) {
+	b, err := s.bucket(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, found := b.uploads[id]
+	if !found {
+		return nil, nil, errNoSuchUpload(id)
+	}
+	return b, u, nil
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, name, key string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	id := uuid.NewString()
+	b.uploads[id] = &upload{key: key, initiated: time.Now().UTC(), contentType: r.Header.Get("Content-Type"), parts: map[int]*object{}}
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{Xmlns: s3Namespace, Bucket: name, Key: key, UploadID: id})
+	return nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, name, id, partNumber string) error {
+	_, u, err := s.upload(name, id)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(partNumber)
+	if err != nil || n < 1 || n > 10000 {
+		return &s3Error{status: http.StatusBadRequest, code: "InvalidArgument", message: "invalid part number"}
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "IncompleteBody", message: err.Error()}
+	}
+	part := newObject(body)
+	u.parts[n] = part
+	w.Header().Set("ETag", part.etag)
+	return nil
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, name, key, id string) error {
+	b, u, err := s.upload(name, id)
+	if err != nil {
+		return err
+	}
+	buf, err := readBody(r)
+	if err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "IncompleteBody", message: err.Error()}
+	}
+	var req struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(buf, &req); err != nil {
+		return &s3Error{status: http.StatusBadRequest, code: "MalformedXML", message: err.Error()}
+	}
+	if len(req.Parts) == 0 {
+		return &s3Error{status: http.StatusBadRequest, code: "MalformedXML", message: "no part"}
+	}
+	var (
+		body []byte
+		sums []byte
+	)
+	for i, p := range req.Parts {
+		part, found := u.parts[p.PartNumber]
+		if !found || part.etag != p.ETag {
+			return &s3Error{status: http.StatusBadRequest, code: "InvalidPart", message: "part " + strconv.Itoa(p.PartNumber) + " not found"}
+		}
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			return &s3Error{status: http.StatusBadRequest, code: "InvalidPartOrder", message: "parts are not in ascending order"}
+		}
+		body = append(body, part.body...)
+		sum, _ := hex.DecodeString(strings.Trim(part.etag, `"`))
+		sums = append(sums, sum...)
+	}
+	obj := newObject(body)
+	sum := md5.Sum(sums) //nolint:gosec
+	obj.etag = `"` + hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(len(req.Parts)) + `"`
+	obj.contentType = u.contentType
+	b.objects[key] = obj
+	delete(b.uploads, id)
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Xmlns: s3Namespace, Bucket: name, Key: key, ETag: obj.etag})
+	return nil
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, name, id string) error {
+	b, _, err := s.upload(name, id)
+	if err != nil {
+		return err
+	}
+	delete(b.uploads, id)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) listMultipartUploads(w http.ResponseWriter, name string) error {
+	b, err := s.bucket(name)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		Key       string
+		UploadID  string `xml:"UploadId"`
+		Initiated string
+	}
+	uploads := lo.MapToSlice(b.uploads, func(id string, u *upload) entry {
+		return entry{Key: u.key, UploadID: id, Initiated: u.initiated.Format("2006-01-02T15:04:05.000Z")}
+	})
+	slices.SortFunc(uploads, func(a, b entry) int { return strings.Compare(a.Key+a.UploadID, b.Key+b.UploadID) })
+	writeXML(w, struct {
+		XMLName     xml.Name `xml:"ListMultipartUploadsResult"`
+		Xmlns       string   `xml:"xmlns,attr"`
+		Bucket      string
+		IsTruncated bool
+		Uploads     []entry `xml:"Upload"`
+	}{Xmlns: s3Namespace, Bucket: name, Uploads: uploads})
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package testserver provides an in-memory fake of a subset of the OUTSCALE APIs (OAPI and OOS), for hermetic tests.
+//
+// The server is stateful: resources created by a call can be read, updated or deleted by the next calls.
+// Authentication is not checked.
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// Region is the region reported by the server.
+	Region = "eu-west-2"
+	// AccountID is the account owning all resources.
+	AccountID = "123456789012"
+
+	oapiPrefix = "/api/v1/"
+	oksPrefix  = "/api/v2/"
+)
+
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	pageSize  int
+	resources map[string][]resource
+	buckets   map[string]*bucket
+	// consoles counts the reads of the console output of VMs.
+	consoles map[string]int
+}
+
+// New starts a new server. It must be closed by calling Close.
+func New() *Server {
+	s := &Server{
+		resources: map[string][]resource{},
+		buckets:   map[string]*bucket{},
+		consoles:  map[string]int{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// SetPageSize sets the maximum number of items returned by a listing, when the page size is not set by the call.
+// 0, the default, means no limit for OAPI and 1000 for OOS.
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(r.URL.Path, oapiPrefix):
+		s.serveOAPI(w, r, strings.TrimPrefix(r.URL.Path, oapiPrefix))
+	case strings.HasPrefix(r.URL.Path, oksPrefix):
+		writeOAPIError(w, errNotImplemented("OKS"))
+	default:
+		s.serveOOS(w, r)
+	}
+}
+
+// Profile returns a profile, in the format of the profile file, having all endpoints redirected to the server.
+func (s *Server) Profile() map[string]any {
+	return map[string]any{
+		"access_key": "AKTESTSERVER",
+		"secret_key": "SKTESTSERVER",
+		"region":     Region,
+		"endpoints": map[string]string{
+			"api": s.URL + strings.TrimSuffix(oapiPrefix, "/"),
+			"oos": s.URL,
+			"oks": s.URL + strings.TrimSuffix(oksPrefix, "/"),
+		},
+	}
+}
+
+// WriteProfile writes a profile file at path, storing a profile named name, targeting the server.
+func (s *Server) WriteProfile(path, name string) error {
+	buf, err := json.MarshalIndent(map[string]any{name: s.Profile()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal profile: %w", err)
+	}
+	err = os.WriteFile(path, buf, 0o600)
+	if err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	return nil
+}
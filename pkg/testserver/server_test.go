@@ -0,0 +1,266 @@
+/*
+SPDX-FileCopyrightText: 2026 Outscale SAS <opensource@outscale.com>
+SPDX-License-Identifier: BSD-3-Clause
+*/
+package testserver_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/outscale/octl/pkg/testserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func call(t *testing.T, srv *testserver.Server, op string, req map[string]any) (int, map[string]any) {
+	t.Helper()
+	buf, err := json.Marshal(req)
+	require.NoError(t, err)
+	resp, err := http.Post(srv.URL+"/api/v1/"+op, "application/json", bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint
+	var res map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+	return resp.StatusCode, res
+}
+
+func mustCall(t *testing.T, srv *testserver.Server, op string, req map[string]any) map[string]any {
+	t.Helper()
+	status, res := call(t, srv, op, req)
+	require.Equal(t, http.StatusOK, status, res)
+	return res
+}
+
+func TestOAPI(t *testing.T) {
+	srv := testserver.New()
+	defer srv.Close()
+
+	net := mustCall(t, srv, "CreateNet", map[string]any{"IpRange": "10.0.0.0/16"})["Net"].(map[string]any)
+	netID := net["NetId"].(string)
+	subnet := mustCall(t, srv, "CreateSubnet", map[string]any{"NetId": netID, "IpRange": "10.0.1.0/24"})["Subnet"].(map[string]any)
+	subnetID := subnet["SubnetId"].(string)
+	vms := mustCall(t, srv, "CreateVms", map[string]any{"ImageId": "ami-foo", "SubnetId": subnetID, "MaxVmsCount": 3})["Vms"].([]any)
+	require.Len(t, vms, 3)
+
+	t.Run("Listings are paginated", func(t *testing.T) {
+		var ids []string
+		req := map[string]any{"Filters": map[string]any{"NetIds": []string{netID}}, "ResultsPerPage": 2}
+		for {
+			res := mustCall(t, srv, "ReadVms", req)
+			for _, vm := range res["Vms"].([]any) {
+				ids = append(ids, vm.(map[string]any)["VmId"].(string))
+			}
+			token, ok := res["NextPageToken"]
+			if !ok {
+				break
+			}
+			req["NextPageToken"] = token
+		}
+		assert.Len(t, ids, 3)
+	})
+	t.Run("VMs become running", func(t *testing.T) {
+		res := mustCall(t, srv, "ReadVms", map[string]any{"Filters": map[string]any{"VmStateNames": []string{"running"}}})
+		assert.Len(t, res["Vms"], 3)
+	})
+	t.Run("Unknown listings are empty", func(t *testing.T) {
+		res := mustCall(t, srv, "ReadInternetServices", nil)
+		assert.Empty(t, res["InternetServices"])
+	})
+	t.Run("Unknown reads are empty", func(t *testing.T) {
+		res := mustCall(t, srv, "ReadApiAccessPolicy", nil)
+		assert.Empty(t, res["ApiAccessPolicy"])
+	})
+	t.Run("The account is the account of the server", func(t *testing.T) {
+		res := mustCall(t, srv, "ReadAccounts", nil)
+		require.Len(t, res["Accounts"], 1)
+		assert.Equal(t, testserver.AccountID, res["Accounts"].([]any)[0].(map[string]any)["AccountId"])
+	})
+	t.Run("Tags can be set and read", func(t *testing.T) {
+		mustCall(t, srv, "CreateTags", map[string]any{"ResourceIds": []string{netID, subnetID}, "Tags": []map[string]string{{"Key": "Name", "Value": "foo"}}})
+		res := mustCall(t, srv, "ReadTags", map[string]any{"Filters": map[string]any{"ResourceTypes": []string{"vpc"}}})
+		require.Len(t, res["Tags"], 1)
+		assert.Equal(t, netID, res["Tags"].([]any)[0].(map[string]any)["ResourceId"])
+		res = mustCall(t, srv, "ReadSubnets", map[string]any{"Filters": map[string]any{"Tags": []string{"Name=foo"}}})
+		assert.Len(t, res["Subnets"], 1)
+	})
+	t.Run("Security group rules can be added and deleted", func(t *testing.T) {
+		sg := mustCall(t, srv, "CreateSecurityGroup", map[string]any{"SecurityGroupName": "web", "Description": "web", "NetId": netID})["SecurityGroup"].(map[string]any)
+		res := mustCall(t, srv, "CreateSecurityGroupRule", map[string]any{
+			"SecurityGroupId": sg["SecurityGroupId"], "Flow": "Inbound",
+			"IpProtocol": "tcp", "FromPortRange": 22, "ToPortRange": 22, "IpRange": "0.0.0.0/0",
+		})
+		rules := res["SecurityGroup"].(map[string]any)["InboundRules"].([]any)
+		require.Len(t, rules, 1)
+		assert.Equal(t, []any{"0.0.0.0/0"}, rules[0].(map[string]any)["IpRanges"])
+		res = mustCall(t, srv, "DeleteSecurityGroupRule", map[string]any{
+			"SecurityGroupId": sg["SecurityGroupId"], "Flow": "Inbound",
+			"Rules": []any{map[string]any{"IpProtocol": "tcp", "FromPortRange": 22, "ToPortRange": 22, "IpRanges": []any{"0.0.0.0/0"}}},
+		})
+		assert.Empty(t, res["SecurityGroup"].(map[string]any)["InboundRules"])
+		mustCall(t, srv, "DeleteSecurityGroup", map[string]any{"SecurityGroupId": sg["SecurityGroupId"]})
+	})
+	t.Run("The console output of VMs grows", func(t *testing.T) {
+		vmID := vms[0].(map[string]any)["VmId"]
+		var out []byte
+		for range 3 {
+			res := mustCall(t, srv, "ReadConsoleOutput", map[string]any{"VmId": vmID})
+			var err error
+			out, err = base64.StdEncoding.DecodeString(res["ConsoleOutput"].(string))
+			require.NoError(t, err)
+		}
+		assert.Contains(t, string(out), "boot step 3\n")
+		assert.Contains(t, string(out), "Cloud-init v. 24.1 finished\n")
+	})
+	t.Run("Resources having dependencies cannot be deleted", func(t *testing.T) {
+		status, res := call(t, srv, "DeleteSubnet", map[string]any{"SubnetId": subnetID})
+		assert.Equal(t, http.StatusConflict, status)
+		assert.NotEmpty(t, res["Errors"])
+	})
+	t.Run("Resources can be deleted", func(t *testing.T) {
+		ids := []string{}
+		for _, vm := range vms {
+			ids = append(ids, vm.(map[string]any)["VmId"].(string))
+		}
+		mustCall(t, srv, "DeleteVms", map[string]any{"VmIds": ids})
+		mustCall(t, srv, "DeleteSubnet", map[string]any{"SubnetId": subnetID})
+		mustCall(t, srv, "DeleteNet", map[string]any{"NetId": netID})
+		status, _ := call(t, srv, "DeleteNet", map[string]any{"NetId": netID})
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestOOS(t *testing.T) {
+	srv := testserver.New()
+	defer srv.Close()
+	cl := s3.New(s3.Options{
+		BaseEndpoint: aws.String(srv.URL),
+		Region:       testserver.Region,
+		Credentials:  credentials.NewStaticCredentialsProvider("ak", "sk", ""),
+		UsePathStyle: true,
+	})
+	ctx := t.Context()
+	bucket := aws.String("bucket")
+
+	_, err := cl.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: bucket})
+	require.NoError(t, err)
+	for _, key := range []string{"a.txt", "b.txt", "dir/c.txt"} {
+		_, err := cl.PutObject(ctx, &s3.PutObjectInput{Bucket: bucket, Key: aws.String(key), Body: strings.NewReader("content of " + key)})
+		require.NoError(t, err)
+	}
+
+	t.Run("Listings are paginated", func(t *testing.T) {
+		var keys []string
+		p := s3.NewListObjectsV2Paginator(cl, &s3.ListObjectsV2Input{Bucket: bucket, MaxKeys: aws.Int32(2)})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			require.NoError(t, err)
+			for _, o := range page.Contents {
+				keys = append(keys, *o.Key)
+			}
+		}
+		assert.Equal(t, []string{"a.txt", "b.txt", "dir/c.txt"}, keys)
+	})
+	t.Run("Listings may use a delimiter", func(t *testing.T) {
+		res, err := cl.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: bucket, Delimiter: aws.String("/")})
+		require.NoError(t, err)
+		assert.Len(t, res.Contents, 2)
+		require.Len(t, res.CommonPrefixes, 1)
+		assert.Equal(t, "dir/", *res.CommonPrefixes[0].Prefix)
+	})
+	t.Run("Objects can be read and copied", func(t *testing.T) {
+		_, err := cl.CopyObject(ctx, &s3.CopyObjectInput{Bucket: bucket, Key: aws.String("copy.txt"), CopySource: aws.String("bucket/a.txt")})
+		require.NoError(t, err)
+		res, err := cl.GetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String("copy.txt")})
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint
+		buf, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "content of a.txt", string(buf))
+	})
+	t.Run("Objects can be uploaded in multiple parts", func(t *testing.T) {
+		up, err := cl.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: bucket, Key: aws.String("multi.txt")})
+		require.NoError(t, err)
+		var parts []types.CompletedPart
+		for i, content := range []string{"first ", "second"} {
+			res, err := cl.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket: bucket, Key: aws.String("multi.txt"), UploadId: up.UploadId, PartNumber: aws.Int32(int32(i + 1)), Body: strings.NewReader(content),
+			})
+			require.NoError(t, err)
+			parts = append(parts, types.CompletedPart{PartNumber: aws.Int32(int32(i + 1)), ETag: res.ETag})
+		}
+		list, err := cl.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{Bucket: bucket})
+		require.NoError(t, err)
+		require.Len(t, list.Uploads, 1)
+		_, err = cl.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket: bucket, Key: aws.String("multi.txt"), UploadId: up.UploadId, MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		require.NoError(t, err)
+		res, err := cl.GetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String("multi.txt")})
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint
+		buf, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "first second", string(buf))
+		assert.True(t, strings.HasSuffix(*res.ETag, `-2"`), *res.ETag)
+
+		up, err = cl.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: bucket, Key: aws.String("aborted.txt")})
+		require.NoError(t, err)
+		_, err = cl.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{Bucket: bucket, Key: aws.String("aborted.txt"), UploadId: up.UploadId})
+		require.NoError(t, err)
+		list, err = cl.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{Bucket: bucket})
+		require.NoError(t, err)
+		assert.Empty(t, list.Uploads)
+		_, err = cl.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String("multi.txt")})
+		require.NoError(t, err)
+	})
+	t.Run("Objects can be accessed with presigned URLs", func(t *testing.T) {
+		pcl := s3.NewPresignClient(cl)
+		put, err := pcl.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: bucket, Key: aws.String("presigned.txt")})
+		require.NoError(t, err)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, put.URL, strings.NewReader("presigned"))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		get, err := pcl.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String("presigned.txt")})
+		require.NoError(t, err)
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, get.URL, nil)
+		require.NoError(t, err)
+		res, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint
+		buf, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "presigned", string(buf))
+		_, err = cl.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String("presigned.txt")})
+		require.NoError(t, err)
+	})
+	t.Run("A non empty bucket cannot be deleted", func(t *testing.T) {
+		_, err := cl.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: bucket})
+		require.Error(t, err)
+	})
+	t.Run("Objects and buckets can be deleted", func(t *testing.T) {
+		_, err := cl.DeleteObjects(ctx, &s3.DeleteObjectsInput{Bucket: bucket, Delete: &types.Delete{Objects: []types.ObjectIdentifier{
+			{Key: aws.String("a.txt")}, {Key: aws.String("b.txt")}, {Key: aws.String("dir/c.txt")},
+		}}})
+		require.NoError(t, err)
+		_, err = cl.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String("copy.txt")})
+		require.NoError(t, err)
+		_, err = cl.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: bucket})
+		require.NoError(t, err)
+		_, err = cl.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: bucket})
+		require.Error(t, err)
+	})
+}